@@ -0,0 +1,531 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Relation is an ontology relation that can be followed by ancestry queries.
+type Relation struct {
+	// Name is the OBO name of the relation.
+	Name string
+
+	// ID is the OBO identifier of the relation's
+	// property. ID is empty for the is_a relation.
+	ID string
+}
+
+// Ontology relations.
+var (
+	IsA = Relation{Name: "is_a"}
+
+	PartOf              = Relation{Name: "part_of", ID: "BFO:0000050"}
+	HasPart             = Relation{Name: "has_part", ID: "BFO:0000051"}
+	OccursIn            = Relation{Name: "occurs_in", ID: "BFO:0000066"}
+	Regulates           = Relation{Name: "regulates", ID: "RO:0002211"}
+	NegativelyRegulates = Relation{Name: "negatively_regulates", ID: "RO:0002212"}
+	PositivelyRegulates = Relation{Name: "positively_regulates", ID: "RO:0002213"}
+	HappensDuring       = Relation{Name: "happens_during", ID: "RO:0002092"}
+	EndsDuring          = Relation{Name: "ends_during", ID: "RO:0002093"}
+	CapableOf           = Relation{Name: "capable_of", ID: "RO:0002215"}
+	CapableOfPartOf     = Relation{Name: "capable_of_part_of", ID: "RO:0002216"}
+)
+
+// knownRelations is the set of relations with built-in identifiers.
+var knownRelations = []Relation{
+	PartOf, HasPart, OccursIn,
+	Regulates, NegativelyRegulates, PositivelyRegulates,
+	HappensDuring, EndsDuring,
+	CapableOf, CapableOfPartOf,
+}
+
+// Commonly used relation sets.
+var (
+	// IsAPartOf is the set of is_a and part_of
+	// relations.
+	IsAPartOf = []Relation{IsA, PartOf}
+
+	// RegulatesClosure is the set of relations
+	// followed by the GO regulates closure.
+	RegulatesClosure = []Relation{IsA, PartOf, Regulates, NegativelyRegulates, PositivelyRegulates}
+)
+
+// Ancestry provides ancestry queries over a Graph that follow a set of
+// ontology relations. The is_a relation is represented by rdfs:subClassOf
+// statements between terms, and all other relations are represented by
+// rdfs:subClassOf statements to owl:Restriction blank nodes with an
+// owl:onProperty of the relation's property and an owl:someValuesFrom of
+// the related term, as in the OWL rendering of OBO ontologies. Ancestry
+// queries see through these restrictions.
+type Ancestry struct {
+	// Graph is the graph to query.
+	Graph *Graph
+
+	// Relations is the set of relations to
+	// follow. If Relations is empty, only
+	// is_a relations are followed.
+	Relations []Relation
+
+	// ExcludeDeprecated specifies that
+	// terms marked as deprecated are not
+	// reached by queries.
+	ExcludeDeprecated bool
+
+	// Ontology is the vocabulary of the
+	// ontology to query. If Ontology is
+	// nil, the graph's ontology is used.
+	Ontology *Ontology
+}
+
+// link is a relation between two ontology terms.
+type link struct {
+	term rdf.Term
+	rel  Relation
+}
+
+// ontology returns the ontology vocabulary queried by a.
+func (a Ancestry) ontology() Ontology {
+	if a.Ontology != nil {
+		return *a.Ontology
+	}
+	return a.Graph.Ontology()
+}
+
+// termPrefix returns the ontology term prefix for the graph's namespace
+// form. If the namespace is not known, ok is false.
+func (a Ancestry) termPrefix() (string, bool) {
+	return a.ontology().termPrefix(a.Graph.namespace)
+}
+
+// properties returns a map from the property term values of the relations
+// followed by a to their relations, and whether is_a is followed.
+func (a Ancestry) properties() (props map[string]Relation, isA bool) {
+	if len(a.Relations) == 0 {
+		return nil, true
+	}
+	props = make(map[string]Relation)
+	for _, r := range a.Relations {
+		if r.ID == "" {
+			isA = true
+			continue
+		}
+		props[iriTerm(a.Graph.namespace, oboQName(r.ID, ""))] = r
+	}
+	return props, isA
+}
+
+// parents returns the terms directly related to t by the relations followed
+// by a where t is the subject of the relation.
+func (a Ancestry) parents(t rdf.Term) []link {
+	termPrefix, ok := a.termPrefix()
+	if !ok {
+		return nil
+	}
+	props, isA := a.properties()
+	var parents []link
+	for _, o := range a.Graph.objects(t, a.ontology().subClassOf()) {
+		if strings.HasPrefix(o.Value, termPrefix) {
+			if isA {
+				parents = append(parents, link{term: o, rel: IsA})
+			}
+			continue
+		}
+		if len(props) == 0 || isIRI(o) {
+			continue
+		}
+		prop, filler, ok := a.Graph.someValuesFrom(o)
+		if !ok || !strings.HasPrefix(filler.Value, termPrefix) {
+			continue
+		}
+		if r, ok := props[prop.Value]; ok {
+			parents = append(parents, link{term: filler, rel: r})
+		}
+	}
+	return a.current(parents)
+}
+
+// children returns the terms directly related to t by the relations followed
+// by a where t is the object of the relation.
+func (a Ancestry) children(t rdf.Term) []link {
+	termPrefix, ok := a.termPrefix()
+	if !ok {
+		return nil
+	}
+	props, isA := a.properties()
+	var children []link
+	if isA {
+		for _, s := range a.Graph.subjects(t, a.ontology().subClassOf()) {
+			if strings.HasPrefix(s.Value, termPrefix) {
+				children = append(children, link{term: s, rel: IsA})
+			}
+		}
+	}
+	if len(props) == 0 {
+		return a.current(children)
+	}
+	for _, res := range a.Graph.subjects(t, "owl:someValuesFrom") {
+		if isIRI(res) {
+			continue
+		}
+		prop, _, ok := a.Graph.someValuesFrom(res)
+		if !ok {
+			continue
+		}
+		r, ok := props[prop.Value]
+		if !ok {
+			continue
+		}
+		for _, s := range a.Graph.subjects(res, a.ontology().subClassOf()) {
+			if strings.HasPrefix(s.Value, termPrefix) {
+				children = append(children, link{term: s, rel: r})
+			}
+		}
+	}
+	return a.current(children)
+}
+
+// current returns the links in l that are to terms that are not deprecated
+// if a excludes deprecated terms, and l otherwise.
+func (a Ancestry) current(l []link) []link {
+	if !a.ExcludeDeprecated {
+		return l
+	}
+	o := a.ontology()
+	n := 0
+	for _, e := range l {
+		if !a.Graph.isObsolete(e.term, o) {
+			l[n] = e
+			n++
+		}
+	}
+	return l[:n]
+}
+
+// walk performs a breadth first walk from t following the links returned
+// by next, calling visit for each term reached, including t, with its
+// depth from t. The walk terminates if visit returns true.
+func walk(t rdf.Term, next func(rdf.Term) []link, visit func(rdf.Term, int) bool) {
+	seen := map[int64]bool{t.UID: true}
+	level := []rdf.Term{t}
+	for depth := 0; len(level) != 0; depth++ {
+		var nextLevel []rdf.Term
+		for _, u := range level {
+			if visit(u, depth) {
+				return
+			}
+			for _, l := range next(u) {
+				if seen[l.term.UID] {
+					continue
+				}
+				seen[l.term.UID] = true
+				nextLevel = append(nextLevel, l.term)
+			}
+		}
+		level = nextLevel
+	}
+}
+
+// isTerm returns whether t is a term of the queried ontology.
+func (a Ancestry) isTerm(t rdf.Term) bool {
+	return a.ontology().isTerm(a.Graph.namespace, t)
+}
+
+// inGraph returns whether t is a term of the queried ontology that is
+// held by the graph with the UID of t.
+func (a Ancestry) inGraph(t rdf.Term) bool {
+	n, ok := a.Graph.Node(t.UID).(rdf.Term)
+	return ok && n.Value == t.Value && a.isTerm(t)
+}
+
+// ClosestCommonAncestor returns the term that is the closest common ancestor
+// of x and y if it exists in the graph. The closest common ancestor is the
+// lowest common ancestor with the shortest combined path length from x and
+// y. Ties are broken in favour of the deepest term, and then by term value.
+// If x or y is not a term in the graph, ok is false.
+func (a Ancestry) ClosestCommonAncestor(x, y rdf.Term) (r rdf.Term, ok bool) {
+	if !a.inGraph(x) || !a.inGraph(y) {
+		return r, false
+	}
+	if x == y {
+		return x, true
+	}
+
+	dx := a.distances(x)
+	dy := a.distances(y)
+	lcas := a.lowest(dx, dy)
+	if len(lcas) == 0 {
+		return r, false
+	}
+	depth := make(map[int64]int, len(lcas))
+	for _, t := range lcas {
+		depth[t.UID] = a.Depth(t)
+	}
+	sort.SliceStable(lcas, func(i, j int) bool {
+		ti, tj := lcas[i], lcas[j]
+		di := dx[ti.UID] + dy[ti.UID]
+		dj := dx[tj.UID] + dy[tj.UID]
+		if di != dj {
+			return di < dj
+		}
+		return depth[ti.UID] > depth[tj.UID]
+	})
+	return lcas[0], true
+}
+
+// LowestCommonAncestors returns the lowest common ancestors of x and y,
+// sorted by term value. A lowest common ancestor is a common ancestor of x
+// and y that has no descendant that is also a common ancestor of x and y.
+// Terms are considered to be their own ancestors, so if x is an ancestor of
+// y, the only lowest common ancestor is x. If x or y is not a term in the
+// graph, LowestCommonAncestors returns nil.
+func (a Ancestry) LowestCommonAncestors(x, y rdf.Term) []rdf.Term {
+	if !a.inGraph(x) || !a.inGraph(y) {
+		return nil
+	}
+	return a.lowest(a.distances(x), a.distances(y))
+}
+
+// RankedLowestCommonAncestors returns the lowest common ancestors of x and
+// y sorted by descending score. Ties are broken by term value. Ranking by
+// depth is obtained by passing a score function that returns the Depth of
+// a term.
+func (a Ancestry) RankedLowestCommonAncestors(x, y rdf.Term, score func(rdf.Term) float64) []rdf.Term {
+	lcas := a.LowestCommonAncestors(x, y)
+	scores := make(map[int64]float64, len(lcas))
+	for _, t := range lcas {
+		scores[t.UID] = score(t)
+	}
+	sort.SliceStable(lcas, func(i, j int) bool {
+		return scores[lcas[i].UID] > scores[lcas[j].UID]
+	})
+	return lcas
+}
+
+// Depth returns the length of the longest path from t to a root of the
+// ontology. Roots have a depth of zero. If t is not an ontology term in
+// the graph, Depth returns -1.
+func (a Ancestry) Depth(t rdf.Term) int {
+	if !a.isTerm(t) {
+		return -1
+	}
+	var depth int
+	for _, r := range relatives(t, a.parents, a.relations()) {
+		if r.max > depth {
+			depth = r.max
+		}
+	}
+	return depth
+}
+
+// distances returns the shortest path lengths from t to each of its
+// ancestors, including t, keyed by UID.
+func (a Ancestry) distances(t rdf.Term) map[int64]int {
+	dist := make(map[int64]int)
+	walk(t, a.parents, func(n rdf.Term, d int) bool {
+		dist[n.UID] = d
+		return false
+	})
+	return dist
+}
+
+// lowest returns the lowest terms common to the ancestor distances dx and
+// dy, sorted by term value.
+func (a Ancestry) lowest(dx, dy map[int64]int) []rdf.Term {
+	common := make(map[int64]bool)
+	for uid := range dx {
+		if _, ok := dy[uid]; ok {
+			common[uid] = true
+		}
+	}
+	notLowest := make(map[int64]bool)
+	for uid := range common {
+		if notLowest[uid] {
+			continue
+		}
+		t, ok := a.Graph.Node(uid).(rdf.Term)
+		if !ok {
+			notLowest[uid] = true
+			continue
+		}
+		walk(t, a.parents, func(n rdf.Term, d int) bool {
+			if d != 0 {
+				notLowest[n.UID] = true
+			}
+			return false
+		})
+	}
+	var lcas []rdf.Term
+	for uid := range common {
+		if notLowest[uid] {
+			continue
+		}
+		if t, ok := a.Graph.Node(uid).(rdf.Term); ok {
+			lcas = append(lcas, t)
+		}
+	}
+	sortTerms(lcas)
+	return lcas
+}
+
+// AncestorsOf returns all of the ancestors of the given term.
+func (a Ancestry) AncestorsOf(t rdf.Term) []Ancestor {
+	if !a.isTerm(t) {
+		return nil
+	}
+	rels := a.relations()
+	var anc []Ancestor
+	for _, r := range relatives(t, a.parents, rels) {
+		anc = append(anc, Ancestor{
+			Term:      r.term,
+			MinDepth:  r.min,
+			MaxDepth:  r.max,
+			Relations: r.relations(rels),
+		})
+	}
+	return anc
+}
+
+// DescendantsOf returns all of the descendants of the given term.
+func (a Ancestry) DescendantsOf(t rdf.Term) []Descendant {
+	if !a.isTerm(t) {
+		return nil
+	}
+	rels := a.relations()
+	var desc []Descendant
+	for _, r := range relatives(t, a.children, rels) {
+		desc = append(desc, Descendant{
+			Term:      r.term,
+			MinDepth:  r.min,
+			MaxDepth:  r.max,
+			Relations: r.relations(rels),
+		})
+	}
+	return desc
+}
+
+// relations returns the relations followed by a.
+func (a Ancestry) relations() []Relation {
+	if len(a.Relations) == 0 {
+		return []Relation{IsA}
+	}
+	return a.Relations
+}
+
+// relative is a term reachable from a query term.
+type relative struct {
+	term rdf.Term
+
+	// min and max are the shortest and
+	// longest path lengths from the query.
+	min, max int
+
+	// traversed holds whether each relation
+	// is on any path from the query.
+	traversed []bool
+}
+
+// relations returns the traversed relations of r in the order of rels.
+func (r relative) relations(rels []Relation) []Relation {
+	var traversed []Relation
+	for i, ok := range r.traversed {
+		if ok {
+			traversed = append(traversed, rels[i])
+		}
+	}
+	return traversed
+}
+
+// relatives returns the terms reachable from t following the links returned
+// by next, in breadth first order, excluding t. Each relative holds the
+// minimum and maximum path lengths from t and the relations in rels that are
+// on any path from t.
+func relatives(t rdf.Term, next func(rdf.Term) []link, rels []Relation) []relative {
+	index := make(map[Relation]int, len(rels))
+	for i, r := range rels {
+		index[r] = i
+	}
+
+	// Find the reachable terms and their
+	// shortest distances from t.
+	nodes := map[int64]*relative{t.UID: {term: t, traversed: make([]bool, len(rels))}}
+	order := []*relative{nodes[t.UID]}
+	links := make(map[int64][]link)
+	indegree := make(map[int64]int)
+	for i := 0; i < len(order); i++ {
+		u := order[i]
+		for _, l := range next(u.term) {
+			if l.term.UID == t.UID {
+				continue
+			}
+			links[u.term.UID] = append(links[u.term.UID], l)
+			indegree[l.term.UID]++
+			if _, ok := nodes[l.term.UID]; ok {
+				continue
+			}
+			v := &relative{term: l.term, min: u.min + 1, traversed: make([]bool, len(rels))}
+			nodes[l.term.UID] = v
+			order = append(order, v)
+		}
+	}
+
+	// Propagate the longest distances and the
+	// traversed relations in topological order.
+	// Terms in cycles are never reached here and
+	// retain partial values.
+	queue := []int64{t.UID}
+	for len(queue) != 0 {
+		uid := queue[0]
+		queue = queue[1:]
+		u := nodes[uid]
+		for _, l := range links[uid] {
+			v := nodes[l.term.UID]
+			if u.max+1 > v.max {
+				v.max = u.max + 1
+			}
+			for i, ok := range u.traversed {
+				v.traversed[i] = v.traversed[i] || ok
+			}
+			if i, ok := index[l.rel]; ok {
+				v.traversed[i] = true
+			}
+			indegree[l.term.UID]--
+			if indegree[l.term.UID] == 0 {
+				queue = append(queue, l.term.UID)
+			}
+		}
+	}
+
+	rel := make([]relative, 0, len(order)-1)
+	for _, r := range order[1:] {
+		if r.max < r.min {
+			r.max = r.min
+		}
+		rel = append(rel, *r)
+	}
+	return rel
+}
+
+// IsDescendantOf returns whether the query q is a descendant of t and how
+// many levels separate them if it is. If q is not a descendant of t, depth
+// will be negative.
+func (a Ancestry) IsDescendantOf(t, q rdf.Term) (yes bool, depth int) {
+	depth = -1
+	if !a.isTerm(t) || !a.isTerm(q) {
+		return yes, depth
+	}
+	walk(q, a.parents, func(n rdf.Term, d int) bool {
+		if n == t {
+			yes = true
+			depth = d
+			return true
+		}
+		return false
+	})
+	return yes, depth
+}
@@ -0,0 +1,465 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+const ancestryOBO = `format-version: 1.4
+ontology: go
+
+[Term]
+id: GO:0008150
+name: biological_process
+
+[Term]
+id: GO:0065007
+name: biological regulation
+is_a: GO:0008150
+
+[Term]
+id: GO:0050789
+name: regulation of biological process
+is_a: GO:0065007
+relationship: regulates GO:0008150
+
+[Term]
+id: GO:0009987
+name: cellular process
+is_a: GO:0008150
+
+[Term]
+id: GO:0007154
+name: cell communication
+is_a: GO:0009987
+
+[Term]
+id: GO:0007165
+name: signal transduction
+is_a: GO:0009987
+relationship: part_of GO:0007154
+
+[Term]
+id: GO:0010646
+name: regulation of cell communication
+is_a: GO:0050789
+relationship: regulates GO:0007154
+
+[Term]
+id: GO:0010647
+name: positive regulation of cell communication
+is_a: GO:0010646
+relationship: positively_regulates GO:0007154
+
+[Term]
+id: GO:0005575
+name: cellular_component
+
+[Term]
+id: GO:0005634
+name: nucleus
+is_a: GO:0005575
+relationship: has_part GO:0007154
+`
+
+// oboGraph returns a graph holding the OBO ontology in src. If local is true
+// the graph uses the local namespace form.
+func oboGraph(t *testing.T, src string, local bool) *gogo.Graph {
+	t.Helper()
+	g := gogo.NewGraph()
+	if local {
+		var err error
+		g, _, err = graphFromReader(strings.NewReader(`<obo:GO_0008150> <rdfs:label> "biological_process" .`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	_, err := g.LoadOBO(strings.NewReader(src), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func goTerm(id string, local bool) string {
+	if local {
+		return "<obo:GO_" + id + ">"
+	}
+	return "<http://purl.obolibrary.org/obo/GO_" + id + ">"
+}
+
+var descendantsOfTests = []struct {
+	name      string
+	relations []gogo.Relation
+	term      string
+	want      []string
+}{
+	{
+		name: "is_a",
+		term: "0007154",
+		want: nil,
+	},
+	{
+		name:      "is_a part_of",
+		relations: gogo.IsAPartOf,
+		term:      "0007154",
+		want:      []string{"0007165 1"},
+	},
+	{
+		name:      "regulates closure",
+		relations: gogo.RegulatesClosure,
+		term:      "0007154",
+		want:      []string{"0007165 1", "0010646 1", "0010647 1"},
+	},
+	{
+		name:      "has_part",
+		relations: []gogo.Relation{gogo.HasPart},
+		term:      "0007154",
+		want:      []string{"0005634 1"},
+	},
+	{
+		name: "is_a root",
+		term: "0008150",
+		want: []string{
+			"0007154 2", "0007165 2", "0009987 1", "0010646 3",
+			"0010647 4", "0050789 2", "0065007 1",
+		},
+	},
+	{
+		name:      "regulates closure root",
+		relations: gogo.RegulatesClosure,
+		term:      "0008150",
+		want: []string{
+			"0007154 2", "0007165 2", "0009987 1", "0010646 2",
+			"0010647 3", "0050789 1", "0065007 1",
+		},
+	},
+}
+
+func TestAncestryDescendantsOf(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		for _, test := range descendantsOfTests {
+			term, ok := g.TermFor(goTerm(test.term, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.term)
+			}
+			var got []string
+			for _, d := range (gogo.Ancestry{Graph: g, Relations: test.relations}).DescendantsOf(term) {
+				id := d.Term.Value[strings.Index(d.Term.Value, "GO_")+3 : len(d.Term.Value)-1]
+				got = append(got, fmt.Sprintf("%s %d", id, d.MinDepth))
+			}
+			sort.Strings(got)
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected descendants for %q local=%t:\ngot: %v\nwant:%v", test.name, local, got, test.want)
+			}
+		}
+	}
+}
+
+var isDescendantOfTests = []struct {
+	name      string
+	relations []gogo.Relation
+	a, q      string
+	wantYes   bool
+	wantDepth int
+}{
+	{
+		name: "is_a", a: "0007154", q: "0010647",
+		wantYes: false, wantDepth: -1,
+	},
+	{
+		name: "regulates closure", relations: gogo.RegulatesClosure, a: "0007154", q: "0010647",
+		wantYes: true, wantDepth: 1,
+	},
+	{
+		name: "is_a part_of", relations: gogo.IsAPartOf, a: "0009987", q: "0007165",
+		wantYes: true, wantDepth: 1,
+	},
+	{
+		name: "part_of only", relations: []gogo.Relation{gogo.PartOf}, a: "0009987", q: "0007165",
+		wantYes: false, wantDepth: -1,
+	},
+}
+
+func TestAncestryIsDescendantOf(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		for _, test := range isDescendantOfTests {
+			a, ok := g.TermFor(goTerm(test.a, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.a)
+			}
+			q, ok := g.TermFor(goTerm(test.q, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.q)
+			}
+			yes, depth := gogo.Ancestry{Graph: g, Relations: test.relations}.IsDescendantOf(a, q)
+			if yes != test.wantYes || depth != test.wantDepth {
+				t.Errorf("unexpected result for %q local=%t: got:%t %d want:%t %d",
+					test.name, local, yes, depth, test.wantYes, test.wantDepth)
+			}
+		}
+	}
+}
+
+func TestAncestryClosestCommonAncestor(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		a, _ := g.TermFor(goTerm("0007165", local))
+		b, _ := g.TermFor(goTerm("0010646", local))
+
+		got, ok := g.ClosestCommonAncestor(a, b)
+		want := goTerm("0008150", local)
+		if !ok || got.Value != want {
+			t.Errorf("unexpected is_a closest common ancestor local=%t: got:%s want:%s", local, got.Value, want)
+		}
+
+		got, ok = gogo.Ancestry{Graph: g, Relations: gogo.RegulatesClosure}.ClosestCommonAncestor(a, b)
+		want = goTerm("0007154", local)
+		if !ok || got.Value != want {
+			t.Errorf("unexpected regulates closest common ancestor local=%t: got:%s want:%s", local, got.Value, want)
+		}
+	}
+}
+
+var ancestorsOfTests = []struct {
+	name      string
+	relations []gogo.Relation
+	term      string
+	want      []string
+}{
+	{
+		name: "is_a",
+		term: "0010647",
+		want: []string{
+			"0008150 4 4 is_a",
+			"0010646 1 1 is_a",
+			"0050789 2 2 is_a",
+			"0065007 3 3 is_a",
+		},
+	},
+	{
+		name:      "regulates closure",
+		relations: gogo.RegulatesClosure,
+		term:      "0010647",
+		want: []string{
+			"0007154 1 2 is_a,regulates,positively_regulates",
+			"0008150 3 4 is_a,regulates,positively_regulates",
+			"0009987 2 3 is_a,regulates,positively_regulates",
+			"0010646 1 1 is_a",
+			"0050789 2 2 is_a",
+			"0065007 3 3 is_a",
+		},
+	},
+	{
+		name:      "is_a part_of",
+		relations: gogo.IsAPartOf,
+		term:      "0007165",
+		want: []string{
+			"0007154 1 1 part_of",
+			"0008150 2 3 is_a,part_of",
+			"0009987 1 2 is_a,part_of",
+		},
+	},
+	{
+		name: "root",
+		term: "0008150",
+		want: nil,
+	},
+}
+
+func TestAncestryAncestorsOf(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		for _, test := range ancestorsOfTests {
+			term, ok := g.TermFor(goTerm(test.term, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.term)
+			}
+			var got []string
+			for _, a := range (gogo.Ancestry{Graph: g, Relations: test.relations}).AncestorsOf(term) {
+				id := a.Term.Value[strings.Index(a.Term.Value, "GO_")+3 : len(a.Term.Value)-1]
+				var rels []string
+				for _, r := range a.Relations {
+					rels = append(rels, r.Name)
+				}
+				got = append(got, fmt.Sprintf("%s %d %d %s", id, a.MinDepth, a.MaxDepth, strings.Join(rels, ",")))
+			}
+			sort.Strings(got)
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected ancestors for %q local=%t:\ngot: %v\nwant:%v", test.name, local, got, test.want)
+			}
+		}
+	}
+}
+
+var lowestCommonAncestorsTests = []struct {
+	name      string
+	relations []gogo.Relation
+	x, y      string
+	want      []string
+}{
+	{
+		name: "is_a", x: "0007165", y: "0010646",
+		want: []string{"0008150"},
+	},
+	{
+		name: "regulates closure", relations: gogo.RegulatesClosure, x: "0007165", y: "0010646",
+		want: []string{"0007154"},
+	},
+	{
+		name: "multiple", relations: gogo.RegulatesClosure, x: "0010646", y: "0009987",
+		want: []string{"0009987"},
+	},
+	{
+		name: "multiple lowest", relations: gogo.RegulatesClosure, x: "0010647", y: "0050789",
+		want: []string{"0050789"},
+	},
+	{
+		name: "self", x: "0007154", y: "0007154",
+		want: []string{"0007154"},
+	},
+	{
+		name: "unrelated", x: "0007154", y: "0005634",
+		want: nil,
+	},
+}
+
+func TestAncestryLowestCommonAncestors(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		for _, test := range lowestCommonAncestorsTests {
+			x, ok := g.TermFor(goTerm(test.x, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.x)
+			}
+			y, ok := g.TermFor(goTerm(test.y, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.y)
+			}
+			var got []string
+			for _, a := range (gogo.Ancestry{Graph: g, Relations: test.relations}).LowestCommonAncestors(x, y) {
+				got = append(got, a.Value[strings.Index(a.Value, "GO_")+3:len(a.Value)-1])
+			}
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected lowest common ancestors for %q local=%t:\ngot: %v\nwant:%v", test.name, local, got, test.want)
+			}
+		}
+	}
+}
+
+func TestAncestryRankedLowestCommonAncestors(t *testing.T) {
+	// Terms with two unrelated parents have two lowest
+	// common ancestors that can be ranked.
+	const diamond = `format-version: 1.4
+ontology: go
+
+[Term]
+id: GO:0000001
+name: root
+
+[Term]
+id: GO:0000002
+name: shallow
+is_a: GO:0000001
+
+[Term]
+id: GO:0000003
+name: middle
+is_a: GO:0000001
+
+[Term]
+id: GO:0000004
+name: deep
+is_a: GO:0000003
+
+[Term]
+id: GO:0000005
+name: left
+is_a: GO:0000002
+is_a: GO:0000004
+
+[Term]
+id: GO:0000006
+name: right
+is_a: GO:0000002
+is_a: GO:0000004
+`
+	g := gogo.NewGraph()
+	_, err := g.LoadOBO(strings.NewReader(diamond), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x, _ := g.TermFor(goTerm("0000005", false))
+	y, _ := g.TermFor(goTerm("0000006", false))
+
+	a := gogo.Ancestry{Graph: g}
+	var got []string
+	for _, t := range a.LowestCommonAncestors(x, y) {
+		got = append(got, t.Value)
+	}
+	want := []string{goTerm("0000002", false), goTerm("0000004", false)}
+	if !equalStrings(got, want) {
+		t.Errorf("unexpected lowest common ancestors:\ngot: %v\nwant:%v", got, want)
+	}
+
+	got = got[:0]
+	for _, t := range a.RankedLowestCommonAncestors(x, y, func(t rdf.Term) float64 { return float64(a.Depth(t)) }) {
+		got = append(got, t.Value)
+	}
+	want = []string{goTerm("0000004", false), goTerm("0000002", false)}
+	if !equalStrings(got, want) {
+		t.Errorf("unexpected ranked lowest common ancestors:\ngot: %v\nwant:%v", got, want)
+	}
+
+	cca, ok := a.ClosestCommonAncestor(x, y)
+	if !ok || cca.Value != goTerm("0000004", false) {
+		t.Errorf("unexpected closest common ancestor: got:%s want:%s", cca.Value, goTerm("0000004", false))
+	}
+
+	for id, want := range map[string]int{"0000001": 0, "0000002": 1, "0000004": 2, "0000005": 3} {
+		term, _ := g.TermFor(goTerm(id, false))
+		if got := a.Depth(term); got != want {
+			t.Errorf("unexpected depth for %s: got:%d want:%d", id, got, want)
+		}
+	}
+}
+
+func TestAncestryCommonAncestorsNotInGraph(t *testing.T) {
+	g, _, err := graphFromReader(strings.NewReader(`<obo:GO_1> <rdfs:subClassOf> <obo:GO_2> .`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	known, ok := g.TermFor("<obo:GO_1>")
+	if !ok {
+		t.Fatal("missing term for GO_1")
+	}
+	a := gogo.Ancestry{Graph: g}
+	for _, test := range []struct {
+		name string
+		x, y rdf.Term
+	}{
+		{name: "zero UIDs", x: rdf.Term{Value: "<obo:GO_9>"}, y: rdf.Term{Value: "<obo:GO_8>"}},
+		{name: "known and zero UID", x: known, y: rdf.Term{Value: "<obo:GO_8>"}},
+		{name: "other term's UID", x: rdf.Term{Value: "<obo:GO_9>", UID: known.UID}, y: known},
+		{name: "absent UIDs", x: rdf.Term{Value: "<obo:GO_9>", UID: 1000}, y: rdf.Term{Value: "<obo:GO_8>", UID: 1001}},
+	} {
+		if got := a.LowestCommonAncestors(test.x, test.y); got != nil {
+			t.Errorf("unexpected lowest common ancestors for %s: %v", test.name, got)
+		}
+		if got, ok := a.ClosestCommonAncestor(test.x, test.y); ok {
+			t.Errorf("unexpected closest common ancestor for %s: %v", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Annotation is an association between a gene product and an ontology term.
+type Annotation struct {
+	// GeneProduct is the annotated gene product.
+	GeneProduct GeneProduct
+
+	// Negated indicates that the gene product is
+	// annotated as NOT having the relation to the
+	// term.
+	Negated bool
+
+	// Qualifiers holds any qualifiers of the
+	// annotation other than NOT, for example
+	// "contributes_to" or "enables".
+	Qualifiers []string
+
+	// Relation is the relation between the gene
+	// product and the term. For GPAD annotations
+	// it is the RO identifier of the relation and
+	// for GAF annotations it is the relation name
+	// when it is given by a GAF 2.2 qualifier.
+	Relation string
+
+	// TermID is the identifier of the annotated
+	// term, for example "GO:0005515".
+	TermID string
+
+	// Term is the graph term for TermID and
+	// TermKind is how it was resolved. If the
+	// annotation was read without a graph, or
+	// the term could not be resolved, Term is the
+	// zero rdf.Term and TermKind is UnknownID.
+	Term     rdf.Term
+	TermKind IDKind
+
+	// RelationTerm and EvidenceTerm are the graph
+	// terms for GPAD Relation and Evidence
+	// identifiers. They are the zero rdf.Term if
+	// they could not be resolved.
+	RelationTerm rdf.Term
+	EvidenceTerm rdf.Term
+
+	// References holds the supporting references
+	// of the annotation, for example "PMID:1234".
+	References []string
+
+	// Evidence is the evidence for the annotation,
+	// a GO evidence code such as "IDA" for GAF
+	// annotations or an ECO identifier for GPAD
+	// annotations.
+	Evidence string
+
+	// With holds the with/from field of the
+	// annotation. Each element is a set of
+	// identifiers separated by commas.
+	With []string
+
+	// Aspect is the GO aspect of the term.
+	Aspect Aspect
+
+	// InteractingTaxon is the taxon of an
+	// interacting organism, if given.
+	InteractingTaxon string
+
+	// Date is the date the annotation was made.
+	Date time.Time
+
+	// AssignedBy is the database that made the
+	// annotation.
+	AssignedBy string
+
+	// Extensions holds the annotation extensions.
+	// Each element is a set of relational
+	// expressions separated by commas.
+	Extensions []string
+
+	// Properties holds the GPAD annotation
+	// properties as name=value pairs.
+	Properties []string
+}
+
+// GeneProduct is an annotated gene product.
+type GeneProduct struct {
+	// ID is the identifier of the gene product,
+	// for example "UniProtKB:P12345".
+	ID string
+
+	// Symbol is the symbol of the gene product.
+	Symbol string
+
+	// Name is the full name of the gene product.
+	Name string
+
+	// Synonyms holds synonyms of the gene product.
+	Synonyms []string
+
+	// Type is the type of the gene product, for
+	// example "protein" or "SO:0000104".
+	Type string
+
+	// Taxon is the taxon of the gene product,
+	// for example "taxon:9606" or "NCBITaxon:9606".
+	Taxon string
+
+	// Form is the identifier of the specific form
+	// of the gene product that was annotated, if
+	// given.
+	Form string
+
+	// EncodedBy holds the identifiers of the genes
+	// encoding the gene product.
+	EncodedBy []string
+
+	// Parent is the identifier of the parent
+	// protein of a protein isoform or cleavage
+	// product.
+	Parent string
+
+	// Members holds the identifiers of the members
+	// of a protein-containing complex.
+	Members []string
+
+	// Xrefs holds database cross references for
+	// the gene product.
+	Xrefs []string
+
+	// Properties holds the gene product properties
+	// as name=value pairs.
+	Properties []string
+}
+
+// ParseError is the error type returned when an annotation file line
+// cannot be parsed.
+type ParseError struct {
+	// Format is the name of the file format.
+	Format string
+
+	// Line is the line number of the error.
+	Line int
+
+	// Err is the underlying cause of the error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("gogo: %s: line %d: %v", e.Format, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// tabReader reads tab-separated records from a line-based annotation file,
+// collecting header lines.
+type tabReader struct {
+	format string
+	sc     *bufio.Scanner
+	line   int
+
+	// header holds the comment lines that have
+	// been read without their leading marker.
+	header []string
+
+	// fields holds the first record, read
+	// with the header, until it is returned.
+	fields []string
+}
+
+// newTabReader returns a tabReader reading from r. Input compressed with
+// gzip is detected and decompressed. The leading header lines of the
+// input are read before newTabReader returns.
+func newTabReader(r io.Reader, format string) (*tabReader, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	tr := &tabReader{format: format, sc: sc}
+	err = tr.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// readHeader reads the comment and blank lines preceding the first record,
+// collecting the comment lines into the header, and holds the first record
+// for next.
+func (r *tabReader) readHeader() error {
+	fields, err := r.record()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	r.fields = fields
+	return nil
+}
+
+// next returns the fields of the next record. Comment lines starting with
+// '!' are collected into the header and blank lines are skipped. At the end
+// of the input next returns io.EOF.
+func (r *tabReader) next() ([]string, error) {
+	if r.fields != nil {
+		fields := r.fields
+		r.fields = nil
+		return fields, nil
+	}
+	return r.record()
+}
+
+// record reads lines from the input until it reads a record, returning
+// its fields. Comment lines are collected into the header and blank lines
+// are skipped. At the end of the input record returns io.EOF.
+func (r *tabReader) record() ([]string, error) {
+	for r.sc.Scan() {
+		r.line++
+		line := strings.TrimRight(r.sc.Text(), "\r")
+		if strings.HasPrefix(line, "!") {
+			r.header = append(r.header, strings.TrimSpace(line[1:]))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return strings.Split(line, "\t"), nil
+	}
+	err := r.sc.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	return nil, err
+}
+
+// errorf returns a *ParseError for the current line.
+func (r *tabReader) errorf(format string, args ...interface{}) error {
+	return &ParseError{Format: r.format, Line: r.line, Err: fmt.Errorf(format, args...)}
+}
+
+// headerValue returns the value of the first header line with the given
+// tag, for example "gaf-version".
+func (r *tabReader) headerValue(tag string) string {
+	for _, h := range r.header {
+		if strings.HasPrefix(h, tag+":") {
+			return strings.TrimSpace(h[len(tag)+1:])
+		}
+	}
+	return ""
+}
+
+// pipeList returns the elements of the pipe-separated field f, or nil if f is
+// empty.
+func pipeList(f string) []string {
+	if f == "" {
+		return nil
+	}
+	return strings.Split(f, "|")
+}
+
+// resolveTerm sets the Term and TermKind of a to the term in g identified
+// by the annotation's TermID. If g is nil, a is not altered.
+func (a *Annotation) resolveTerm(g *Graph) {
+	if g == nil {
+		return
+	}
+	t, kind, ok := g.Resolve(a.TermID)
+	if !ok {
+		return
+	}
+	a.Term = t
+	a.TermKind = kind
+}
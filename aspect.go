@@ -0,0 +1,111 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Aspect is a GO aspect, the sub-ontology a GO term belongs to.
+type Aspect int
+
+// GO aspects.
+const (
+	UnknownAspect Aspect = iota
+	MolecularFunction
+	CellularComponent
+	BiologicalProcess
+)
+
+// aspectRoots is the root term identifier of each aspect.
+var aspectRoots = [...]string{
+	MolecularFunction: "GO:0003674",
+	CellularComponent: "GO:0005575",
+	BiologicalProcess: "GO:0008150",
+}
+
+// String returns the OBO namespace of the aspect, for example
+// "biological_process".
+func (a Aspect) String() string {
+	switch a {
+	case MolecularFunction:
+		return "molecular_function"
+	case CellularComponent:
+		return "cellular_component"
+	case BiologicalProcess:
+		return "biological_process"
+	default:
+		return "unknown"
+	}
+}
+
+// Code returns the single letter GAF code of the aspect, "F", "C" or "P",
+// or the empty string for an unknown aspect.
+func (a Aspect) Code() string {
+	switch a {
+	case MolecularFunction:
+		return "F"
+	case CellularComponent:
+		return "C"
+	case BiologicalProcess:
+		return "P"
+	default:
+		return ""
+	}
+}
+
+// ParseAspect returns the aspect for an OBO namespace such as
+// "biological_process" or a GAF aspect code such as "P". It returns
+// UnknownAspect if s is not recognised.
+func ParseAspect(s string) Aspect {
+	switch s {
+	case "molecular_function", "F":
+		return MolecularFunction
+	case "cellular_component", "C":
+		return CellularComponent
+	case "biological_process", "P":
+		return BiologicalProcess
+	default:
+		return UnknownAspect
+	}
+}
+
+// Aspect returns the GO aspect of the term t. The oboInOwl:hasOBONamespace
+// of the term is used if it is present, otherwise the aspect is determined
+// by which GO root the term is a descendant of through is_a relations.
+// UnknownAspect is returned if neither approach identifies the aspect.
+func (g *Graph) Aspect(t rdf.Term) Aspect {
+	for _, ns := range g.literals(t, "oboInOwl:hasOBONamespace") {
+		if a := ParseAspect(ns); a != UnknownAspect {
+			return a
+		}
+	}
+
+	ontology := GO
+	anc := Ancestry{Graph: g, Ontology: &ontology}
+	if !anc.isTerm(t) {
+		return UnknownAspect
+	}
+	for a := MolecularFunction; a <= BiologicalProcess; a++ {
+		root, ok := g.TermFor(iriTerm(g.namespace, oboQName(aspectRoots[a], "")))
+		if !ok {
+			continue
+		}
+		if t == root {
+			return a
+		}
+		if ok, _ := anc.IsDescendantOf(root, t); ok {
+			return a
+		}
+	}
+	return UnknownAspect
+}
+
+// Aspect returns a query holding the terms of q that have the GO aspect a.
+func (q Query) Aspect(a Aspect) Query {
+	return q.Filter(func(t rdf.Term) bool {
+		return q.g.Aspect(t) == a
+	})
+}
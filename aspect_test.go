@@ -0,0 +1,77 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var aspectTests = []struct {
+	name string
+	src  string
+	id   string
+	want gogo.Aspect
+}{
+	{name: "namespace", src: testOBO, id: "0007165", want: gogo.BiologicalProcess},
+	{name: "namespace root", src: testOBO, id: "0003674", want: gogo.MolecularFunction},
+	{name: "namespace root", src: testOBO, id: "0005575", want: gogo.CellularComponent},
+	{name: "reachability", src: ancestryOBO, id: "0010647", want: gogo.BiologicalProcess},
+	{name: "reachability", src: ancestryOBO, id: "0005634", want: gogo.CellularComponent},
+	{name: "reachability root", src: ancestryOBO, id: "0008150", want: gogo.BiologicalProcess},
+	{name: "unreachable", src: obsoleteOBO, id: "0000002", want: gogo.UnknownAspect},
+}
+
+func TestAspect(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		for _, test := range aspectTests {
+			g := oboGraph(t, test.src, local)
+			term, ok := g.TermFor(goTerm(test.id, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.id)
+			}
+			got := g.Aspect(term)
+			if got != test.want {
+				t.Errorf("unexpected aspect for %s %q local=%t: got:%v want:%v", test.name, test.id, local, got, test.want)
+			}
+		}
+	}
+}
+
+func TestQueryAspect(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		var terms []rdf.Term
+		for _, id := range []string{"0008150", "0005575", "0005634", "0007154", "0010646"} {
+			term, ok := g.TermFor(goTerm(id, local))
+			if !ok {
+				t.Fatalf("missing term for %q", id)
+			}
+			terms = append(terms, term)
+		}
+		got := termValues(g.Query(terms...).Aspect(gogo.CellularComponent).Result())
+		want := []string{goTerm("0005575", local), goTerm("0005634", local)}
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected cellular component terms local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+	}
+}
+
+func TestParseAspect(t *testing.T) {
+	for _, a := range []gogo.Aspect{gogo.MolecularFunction, gogo.CellularComponent, gogo.BiologicalProcess} {
+		if got := gogo.ParseAspect(a.String()); got != a {
+			t.Errorf("unexpected aspect for %q: got:%v want:%v", a.String(), got, a)
+		}
+		if got := gogo.ParseAspect(a.Code()); got != a {
+			t.Errorf("unexpected aspect for %q: got:%v want:%v", a.Code(), got, a)
+		}
+	}
+	if got := gogo.ParseAspect("X"); got != gogo.UnknownAspect {
+		t.Errorf("unexpected aspect for invalid code: got:%v", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+)
+
+// Bonferroni sets the AdjustedP field of each of the results to the
+// Bonferroni family-wise error rate adjusted p-value. The number of
+// tests is taken to be the number of results.
+func Bonferroni(results []Enrichment) {
+	m := float64(len(results))
+	for i := range results {
+		results[i].AdjustedP = math.Min(1, m*results[i].P)
+	}
+}
+
+// Holm sets the AdjustedP field of each of the results to the Holm
+// step-down family-wise error rate adjusted p-value. The number of
+// tests is taken to be the number of results.
+func Holm(results []Enrichment) {
+	m := len(results)
+	var max float64
+	for rank, i := range byP(results) {
+		p := math.Min(1, float64(m-rank)*results[i].P)
+		max = math.Max(max, p)
+		results[i].AdjustedP = max
+	}
+}
+
+// BenjaminiHochberg sets the AdjustedP field of each of the results to
+// the Benjamini–Hochberg false discovery rate adjusted p-value. The number
+// of tests is taken to be the number of results.
+func BenjaminiHochberg(results []Enrichment) {
+	stepUp(results, 1)
+}
+
+// BenjaminiYekutieli sets the AdjustedP field of each of the results to
+// the Benjamini–Yekutieli false discovery rate adjusted p-value, which
+// is valid under arbitrary dependence between tests. The number of tests
+// is taken to be the number of results.
+func BenjaminiYekutieli(results []Enrichment) {
+	var c float64
+	for i := 1; i <= len(results); i++ {
+		c += 1 / float64(i)
+	}
+	stepUp(results, c)
+}
+
+// stepUp sets the AdjustedP field of each of the results to the
+// Benjamini–Hochberg step-up adjusted p-value scaled by c.
+func stepUp(results []Enrichment, c float64) {
+	m := len(results)
+	order := byP(results)
+	min := 1.0
+	for rank := m - 1; rank >= 0; rank-- {
+		i := order[rank]
+		p := c * float64(m) / float64(rank+1) * results[i].P
+		min = math.Min(min, p)
+		results[i].AdjustedP = min
+	}
+}
+
+// byP returns the indices of results in order of increasing P.
+func byP(results []Enrichment) []int {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return results[order[i]].P < results[order[j]].P
+	})
+	return order
+}
+
+// PermutationFDR sets the AdjustedP field of each of the results to an
+// empirical false discovery rate estimated by resampling. Each of the n
+// permutations tests a study set of the same size as the population gene
+// products in study, drawn uniformly without replacement from the
+// population, using the annotations in s. The false discovery rate for a
+// result is the mean number of permuted tests with a p-value at or below
+// the result's P divided by the number of results with a p-value at or
+// below P, and is made monotonic in P. The study, population and opts
+// should be the same as those used to obtain the results. If src is nil,
+// the global random source is used. PermutationFDR returns an error if
+// opts specifies an invalid method.
+func (s *Annotations) PermutationFDR(results []Enrichment, study, population []string, opts *EnrichmentOptions, n int, src rand.Source) error {
+	if len(results) == 0 || n <= 0 {
+		return nil
+	}
+	pop := s.newEnrichmentPopulation(population)
+	perm := permFunc(src)
+	inStudy := make(map[string]bool, len(study))
+	for _, g := range study {
+		if _, ok := pop.terms[g]; ok {
+			inStudy[g] = true
+		}
+	}
+	size := len(inStudy)
+	sample := make([]string, size)
+	var null []float64
+	for i := 0; i < n; i++ {
+		for j, k := range perm(len(pop.genes))[:size] {
+			sample[j] = pop.genes[k]
+		}
+		r, err := pop.test(sample, opts)
+		if err != nil {
+			return err
+		}
+		for _, e := range r {
+			null = append(null, e.P)
+		}
+	}
+	sort.Float64s(null)
+
+	order := byP(results)
+	min := 1.0
+	for rank := len(order) - 1; rank >= 0; rank-- {
+		i := order[rank]
+		p := results[i].P
+		// Count all results tied with p.
+		observed := rank + 1
+		for observed < len(order) && results[order[observed]].P == p {
+			observed++
+		}
+		nulls := sort.Search(len(null), func(j int) bool { return null[j] > p })
+		fdr := float64(nulls) / float64(n) / float64(observed)
+		min = math.Min(min, fdr)
+		results[i].AdjustedP = min
+	}
+	return nil
+}
+
+// permFunc returns a function returning random permutations drawn from
+// src, or from the global random source if src is nil.
+func permFunc(src rand.Source) func(int) []int {
+	if src == nil {
+		return rand.Perm
+	}
+	return rand.New(src).Perm
+}
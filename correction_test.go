@@ -0,0 +1,210 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+
+	"github.com/kortschak/gogo"
+)
+
+var correctionTests = []struct {
+	name   string
+	adjust func([]gogo.Enrichment)
+	want   []float64
+}{
+	{name: "Bonferroni", adjust: gogo.Bonferroni, want: []float64{0.04, 0.16, 0.12, 0.02}},
+	{name: "Holm", adjust: gogo.Holm, want: []float64{0.03, 0.06, 0.06, 0.02}},
+	{name: "BenjaminiHochberg", adjust: gogo.BenjaminiHochberg, want: []float64{0.02, 0.04, 0.04, 0.02}},
+	{name: "BenjaminiYekutieli", adjust: gogo.BenjaminiYekutieli, want: []float64{0.125 / 3, 0.25 / 3, 0.25 / 3, 0.125 / 3}},
+}
+
+func TestCorrection(t *testing.T) {
+	p := []float64{0.01, 0.04, 0.03, 0.005}
+	for _, test := range correctionTests {
+		results := make([]gogo.Enrichment, len(p))
+		for i, v := range p {
+			results[i].P = v
+		}
+		test.adjust(results)
+		for i, e := range results {
+			if !scalar.EqualWithinAbsOrRel(e.AdjustedP, test.want[i], 1e-12, 1e-12) {
+				t.Errorf("unexpected %s adjusted p-value for p=%v: got:%v want:%v",
+					test.name, e.P, e.AdjustedP, test.want[i])
+			}
+		}
+	}
+}
+
+func TestPermutationFDR(t *testing.T) {
+	g := oboGraph(t, ancestryOBO, false)
+	s := gogo.NewAnnotations(gogo.Ancestry{Graph: g})
+	var study, population []string
+	for i := 0; i < 50; i++ {
+		gene := fmt.Sprintf("p%d", i)
+		term := "GO:0008150"
+		switch {
+		case i < 10:
+			term = "GO:0007165"
+		case i < 20:
+			term = "GO:0010646"
+		}
+		err := s.Add(&gogo.Annotation{GeneProduct: gogo.GeneProduct{ID: gene}, TermID: term})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		population = append(population, gene)
+		if i < 8 || (i >= 20 && i < 24) {
+			study = append(study, gene)
+		}
+	}
+
+	results, err := s.Enrichment(study, population, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("no enrichment results")
+	}
+	err = s.PermutationFDR(results, study, population, nil, 200, rand.NewSource(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := make([]float64, len(results))
+	for i, e := range results {
+		first[i] = e.AdjustedP
+		if e.AdjustedP < 0 || e.AdjustedP > 1 {
+			t.Errorf("adjusted p-value out of range for %s: %v", e.Term.Value, e.AdjustedP)
+		}
+		if i != 0 && e.AdjustedP < results[i-1].AdjustedP {
+			t.Errorf("adjusted p-values not monotonic at %s: %v < %v", e.Term.Value, e.AdjustedP, results[i-1].AdjustedP)
+		}
+	}
+	if results[0].Term.Value != goTerm("0007165", false) {
+		t.Errorf("unexpected most significant term: got:%s", results[0].Term.Value)
+	}
+	if results[0].AdjustedP >= 0.05 {
+		t.Errorf("unexpected false discovery rate for enriched term: got:%v", results[0].AdjustedP)
+	}
+
+	err = s.PermutationFDR(results, study, population, nil, 200, rand.NewSource(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, e := range results {
+		if e.AdjustedP != first[i] {
+			t.Errorf("permutation false discovery rate not reproducible for %s: got:%v want:%v",
+				e.Term.Value, e.AdjustedP, first[i])
+		}
+	}
+}
+
+func TestPermutationFDRStudySize(t *testing.T) {
+	s, study, population := topologyStore(t, false)
+	// The permuted study size is the number of distinct
+	// study gene products in the population, so neither
+	// the repeated nor the unknown gene product count.
+	study = append(study, "p0", "unknown")
+	results, err := s.Enrichment(study, population, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	const n = 50
+	err = s.PermutationFDR(results, study, population, nil, n, rand.NewSource(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := permutationFDR(t, s, results, len(study)-2, population, nil, n, rand.NewSource(1))
+	for i, e := range results {
+		if !scalar.EqualWithinAbsOrRel(e.AdjustedP, want[i], 1e-12, 1e-12) {
+			t.Errorf("unexpected permutation false discovery rate for %s: got:%v want:%v",
+				e.Term.Value, e.AdjustedP, want[i])
+		}
+	}
+}
+
+// permutationFDR returns the expected permutation false discovery rates
+// for the results using n permuted studies of the given size drawn from
+// the population with src.
+func permutationFDR(t *testing.T, s *gogo.Annotations, results []gogo.Enrichment, size int, population []string, opts *gogo.EnrichmentOptions, n int, src rand.Source) []float64 {
+	t.Helper()
+	pop := append([]string(nil), population...)
+	sort.Strings(pop)
+	rnd := rand.New(src)
+	var null []float64
+	for i := 0; i < n; i++ {
+		var sample []string
+		for _, k := range rnd.Perm(len(pop))[:size] {
+			sample = append(sample, pop[k])
+		}
+		perm, err := s.Enrichment(sample, population, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, e := range perm {
+			null = append(null, e.P)
+		}
+	}
+	want := make([]float64, len(results))
+	min := 1.0
+	for i := len(results) - 1; i >= 0; i-- {
+		p := results[i].P
+		var nulls, observed int
+		for _, v := range null {
+			if v <= p {
+				nulls++
+			}
+		}
+		for _, e := range results {
+			if e.P <= p {
+				observed++
+			}
+		}
+		min = math.Min(min, float64(nulls)/float64(n)/float64(observed))
+		want[i] = min
+	}
+	return want
+}
+
+func TestPermutationFDRParentChild(t *testing.T) {
+	s, study, population := topologyStore(t, false)
+	// Add study gene products annotated only to
+	// the root term so that the parent study
+	// sets are smaller than the study.
+	study = append(study, "p30", "p31", "p32", "p33", "p34")
+	opts := &gogo.EnrichmentOptions{Method: gogo.ParentChildUnion}
+	results, err := s.Enrichment(study, population, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, e := range results {
+		if e.StudySize != len(study) || e.PopulationSize != len(population) {
+			t.Errorf("unexpected sizes for %s: got:%d/%d want:%d/%d",
+				e.Term.Value, e.StudySize, e.PopulationSize, len(study), len(population))
+		}
+	}
+	if results[0].ParentStudySize == len(study) {
+		t.Fatalf("parent study size of most significant term is the study size: %d", results[0].ParentStudySize)
+	}
+
+	const n = 50
+	err = s.PermutationFDR(results, study, population, opts, n, rand.NewSource(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := permutationFDR(t, s, results, len(study), population, opts, n, rand.NewSource(1))
+	for i, e := range results {
+		if !scalar.EqualWithinAbsOrRel(e.AdjustedP, want[i], 1e-12, 1e-12) {
+			t.Errorf("unexpected permutation false discovery rate for %s: got:%v want:%v",
+				e.Term.Value, e.AdjustedP, want[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"sort"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// EncodeOptions specifies how statements are written by Encode.
+type EncodeOptions struct {
+	// Compress specifies that the output
+	// should be gzip compressed.
+	Compress bool
+
+	// Graph is the IRI of the named graph
+	// to label statements with. If Graph is
+	// empty, statements are written as
+	// N-Triples, otherwise they are written
+	// as N-Quads.
+	Graph string
+
+	// Canonicalize specifies that blank
+	// nodes should be relabelled using the
+	// URDNA2015 algorithm before writing,
+	// so that graphs that differ only in
+	// their blank node labels are written
+	// identically.
+	Canonicalize bool
+}
+
+// Encode writes all the statements in the graph to w according to opts.
+// If opts is nil, statements are written as uncompressed N-Triples.
+// Statements are written in lexical order with duplicates removed so that
+// the output of graphs holding the same statements is identical. Blank
+// node labels are written as stored unless opts.Canonicalize is true.
+func (g *Graph) Encode(w io.Writer, opts *EncodeOptions) error {
+	var statements []*rdf.Statement
+	it := g.AllStatements()
+	for it.Next() {
+		statements = append(statements, it.Statement())
+	}
+	return encode(w, statements, opts)
+}
+
+// Encode writes the statements induced by the terms held by the query to w
+// with the same semantics as Graph.Encode.
+func (q Query) Encode(w io.Writer, opts *EncodeOptions) error {
+	return encode(w, q.Statements(), opts)
+}
+
+// Statements returns the statements in the query's graph that have both
+// their subject and object held by the query.
+func (q Query) Statements() []*rdf.Statement {
+	held := make(map[int64]bool)
+	for _, t := range q.terms {
+		held[t.UID] = true
+	}
+	var statements []*rdf.Statement
+	for uid := range held {
+		to := q.g.From(uid)
+		for to.Next() {
+			vid := to.Node().ID()
+			if !held[vid] {
+				continue
+			}
+			it := q.g.Statements(uid, vid)
+			for it.Next() {
+				statements = append(statements, it.Statement())
+			}
+		}
+	}
+	return statements
+}
+
+// encode writes the statements to w in canonical order according to opts.
+func encode(w io.Writer, statements []*rdf.Statement, opts *EncodeOptions) error {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	if opts.Canonicalize {
+		var err error
+		statements, err = rdf.URDNA2015(nil, statements)
+		if err != nil {
+			return err
+		}
+	}
+	var label rdf.Term
+	if opts.Graph != "" {
+		var err error
+		label, err = rdf.NewIRITerm(opts.Graph)
+		if err != nil {
+			return err
+		}
+	}
+
+	lines := make([]string, len(statements))
+	for i, s := range statements {
+		c := *s
+		c.Label = label
+		lines[i] = c.String()
+	}
+	sort.Strings(lines)
+
+	var gz *gzip.Writer
+	if opts.Compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	bw := bufio.NewWriter(w)
+	for i, l := range lines {
+		if i != 0 && l == lines[i-1] {
+			continue
+		}
+		_, err := bw.WriteString(l)
+		if err != nil {
+			return err
+		}
+		err = bw.WriteByte('\n')
+		if err != nil {
+			return err
+		}
+	}
+	err := bw.Flush()
+	if err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+const encodeTriples = `
+<ex:c> <ex:p> <ex:a> .
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:b> <ex:q> "b" .
+`
+
+var encodeTests = []struct {
+	name  string
+	query func(*gogo.Graph) gogo.Query
+	opts  *gogo.EncodeOptions
+	want  string
+}{
+	{
+		name: "graph",
+		want: `<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:b> <ex:q> "b" .
+<ex:c> <ex:p> <ex:a> .
+`,
+	},
+	{
+		name: "graph nquads",
+		opts: &gogo.EncodeOptions{Graph: "http://example.com/g"},
+		want: `<ex:a> <ex:p> <ex:b> <http://example.com/g> .
+<ex:b> <ex:p> <ex:c> <http://example.com/g> .
+<ex:b> <ex:q> "b" <http://example.com/g> .
+<ex:c> <ex:p> <ex:a> <http://example.com/g> .
+`,
+	},
+	{
+		name: "graph compressed",
+		opts: &gogo.EncodeOptions{Compress: true},
+		want: `<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:b> <ex:q> "b" .
+<ex:c> <ex:p> <ex:a> .
+`,
+	},
+	{
+		name: "query",
+		query: func(g *gogo.Graph) gogo.Query {
+			a, _ := g.TermFor("<ex:a>")
+			return g.Query(a).Out(func(s *rdf.Statement) bool { return true }).Or(g.Query(a))
+		},
+		want: `<ex:a> <ex:p> <ex:b> .
+`,
+	},
+	{
+		name: "query literal",
+		query: func(g *gogo.Graph) gogo.Query {
+			b, _ := g.TermFor("<ex:b>")
+			return g.Query(b).Out(func(s *rdf.Statement) bool { return s.Predicate.Value == "<ex:q>" }).Or(g.Query(b))
+		},
+		want: `<ex:b> <ex:q> "b" .
+`,
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, test := range encodeTests {
+		g, _, err := graphFromReader(strings.NewReader(encodeTriples))
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", test.name, err)
+		}
+		// Add a duplicate statement.
+		g.AddStatement(&rdf.Statement{
+			Subject:   rdf.Term{Value: "<ex:a>"},
+			Predicate: rdf.Term{Value: "<ex:p>"},
+			Object:    rdf.Term{Value: "<ex:b>"},
+		})
+
+		var buf bytes.Buffer
+		if test.query == nil {
+			err = g.Encode(&buf, test.opts)
+		} else {
+			err = test.query(g).Encode(&buf, test.opts)
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.name, err)
+			continue
+		}
+
+		got := buf.Bytes()
+		if test.opts != nil && test.opts.Compress {
+			r, err := gzip.NewReader(&buf)
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", test.name, err)
+				continue
+			}
+			got, err = ioutil.ReadAll(r)
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", test.name, err)
+				continue
+			}
+		}
+		if string(got) != test.want {
+			t.Errorf("unexpected result for %q:\ngot:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEncodeCanonicalize(t *testing.T) {
+	const triples = `
+<ex:a> <ex:p> _:%[1]s .
+_:%[1]s <ex:q> "x" .
+_:%[1]s <ex:p> _:%[2]s .
+_:%[2]s <ex:q> "y" .
+`
+	var encoded [2][2]string
+	for i, labels := range [][2]string{{"x", "y"}, {"b1", "b0"}} {
+		g, _, err := graphFromReader(strings.NewReader(fmt.Sprintf(triples, labels[0], labels[1])))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for j, canon := range []bool{false, true} {
+			var buf bytes.Buffer
+			err = g.Encode(&buf, &gogo.EncodeOptions{Canonicalize: canon})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			encoded[j][i] = buf.String()
+		}
+	}
+	if encoded[0][0] == encoded[0][1] {
+		t.Error("expected relabelled graphs to differ without canonicalization")
+	}
+	if encoded[1][0] != encoded[1][1] {
+		t.Errorf("unexpected difference between canonicalized graphs:\nfirst:\n%s\nsecond:\n%s", encoded[1][0], encoded[1][1])
+	}
+	if !strings.Contains(encoded[1][0], "_:c14n") {
+		t.Errorf("expected canonical blank node labels:\n%s", encoded[1][0])
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+// EnrichmentOptions specifies how an enrichment analysis is performed.
+type EnrichmentOptions struct {
+	// MinTermSize and MaxTermSize are the
+	// bounds on the number of population
+	// gene products annotated to a term for
+	// the term to be tested. A zero value
+	// indicates no bound.
+	MinTermSize int
+	MaxTermSize int
+
+	// Aspect restricts tested terms to the
+	// given GO aspect. If Aspect is
+	// UnknownAspect, all terms are tested.
+	Aspect Aspect
+
+	// Method is the enrichment algorithm.
+	// The zero value is Classic. Methods
+	// other than those defined by the
+	// package are an error.
+	Method EnrichmentMethod
+
+	// Cutoff is the significance level at
+	// which the Elim method removes gene
+	// products from the ancestors of a term.
+	// If Cutoff is zero, 0.01 is used.
+	Cutoff float64
+}
+
+// Enrichment is the result of an over-representation test for a term.
+type Enrichment struct {
+	// Term is the tested term.
+	Term rdf.Term
+
+	// StudyCount is the number of study gene
+	// products annotated to the term and
+	// StudySize is the number of study gene
+	// products.
+	StudyCount, StudySize int
+
+	// PopulationCount is the number of
+	// population gene products annotated to
+	// the term and PopulationSize is the
+	// number of population gene products.
+	PopulationCount, PopulationSize int
+
+	// ParentStudySize and
+	// ParentPopulationSize are the number of
+	// study and population gene products
+	// annotated to the term's parents that the
+	// term is tested against by the
+	// parent-child methods. They are zero for
+	// other methods and for terms without
+	// parents.
+	ParentStudySize, ParentPopulationSize int
+
+	// FoldEnrichment is the ratio of the
+	// proportion of study gene products
+	// annotated to the term to the proportion
+	// of population gene products annotated
+	// to the term. For terms tested against
+	// their parents, the proportions are of
+	// the parent gene products.
+	FoldEnrichment float64
+
+	// P is the probability of observing at
+	// least StudyCount annotated gene products
+	// in the study under the hypergeometric
+	// distribution, equivalent to a one-sided
+	// Fisher's exact test.
+	P float64
+
+	// AdjustedP is the p-value adjusted for
+	// multiple testing. It is set by the
+	// multiple-testing correction functions.
+	AdjustedP float64
+
+	// Genes holds the sorted identifiers of
+	// the study gene products annotated to
+	// the term.
+	Genes []string
+}
+
+// Enrichment performs a GO term over-representation analysis of the study
+// gene products against the population gene products using the annotations
+// in s after true path propagation. If population is empty, all the gene
+// products in s are used as the population. Study gene products that are
+// not in the population are ignored. Only terms annotated to at least one
+// study gene product after true path propagation are reported; the counts
+// of Elim and Weight results are adjusted after this selection, so their
+// StudyCount may be zero. If opts is nil, all terms are tested using the
+// Classic method. Term size bounds are applied to the number of
+// population gene products annotated to the term before any adjustment
+// made by a topology-aware method. The results are sorted by increasing P
+// and then by term value. Enrichment returns an error if opts specifies an
+// invalid method.
+func (s *Annotations) Enrichment(study, population []string, opts *EnrichmentOptions) ([]Enrichment, error) {
+	return s.newEnrichmentPopulation(population).test(study, opts)
+}
+
+// enrichmentPopulation holds the propagated annotations of a population
+// of gene products so that repeated tests against the population do not
+// need to recompute them.
+type enrichmentPopulation struct {
+	ancestry Ancestry
+
+	// genes is the sorted set of population
+	// gene products.
+	genes []string
+	// terms holds the propagated terms for
+	// each population gene product.
+	terms map[string][]rdf.Term
+	// count holds the number of population
+	// gene products annotated to each term.
+	count map[int64]int
+	// aspects caches term aspects.
+	aspects map[int64]Aspect
+
+	// dag is the annotated term graph used
+	// by the topology-aware methods. It is
+	// constructed on first use.
+	dag *enrichmentDAG
+}
+
+// newEnrichmentPopulation returns the enrichment population for the given
+// gene products. If population is empty, all the gene products in s are
+// used.
+func (s *Annotations) newEnrichmentPopulation(population []string) *enrichmentPopulation {
+	if len(population) == 0 {
+		population = s.Genes()
+	}
+	p := &enrichmentPopulation{
+		ancestry: s.ancestry,
+		terms:    make(map[string][]rdf.Term, len(population)),
+		count:    make(map[int64]int),
+		aspects:  make(map[int64]Aspect),
+	}
+	for _, g := range population {
+		if _, ok := p.terms[g]; ok {
+			continue
+		}
+		terms := s.PropagatedTermsFor(g)
+		p.terms[g] = terms
+		p.genes = append(p.genes, g)
+		for _, t := range terms {
+			p.count[t.UID]++
+		}
+	}
+	sort.Strings(p.genes)
+	return p
+}
+
+// test performs an over-representation analysis of the study gene products
+// against the population.
+func (p *enrichmentPopulation) test(study []string, opts *EnrichmentOptions) ([]Enrichment, error) {
+	if opts == nil {
+		opts = &EnrichmentOptions{}
+	}
+	inStudy := make(map[string]bool, len(study))
+	for _, g := range study {
+		if _, ok := p.terms[g]; ok {
+			inStudy[g] = true
+		}
+	}
+
+	var results []Enrichment
+	switch opts.Method {
+	case Classic:
+		results = p.classic(inStudy)
+	case ParentChildUnion, ParentChildIntersection:
+		results = p.parentChild(inStudy, opts.Method == ParentChildIntersection)
+	case Elim:
+		cutoff := opts.Cutoff
+		if cutoff == 0 {
+			cutoff = 0.01
+		}
+		results = p.elim(inStudy, cutoff)
+	case Weight:
+		results = p.weight(inStudy)
+	default:
+		return nil, fmt.Errorf("gogo: invalid enrichment method: %d", opts.Method)
+	}
+
+	n := 0
+	for _, e := range results {
+		K := p.count[e.Term.UID]
+		if opts.MinTermSize > 0 && K < opts.MinTermSize {
+			continue
+		}
+		if opts.MaxTermSize > 0 && K > opts.MaxTermSize {
+			continue
+		}
+		if opts.Aspect != UnknownAspect && p.aspect(e.Term) != opts.Aspect {
+			continue
+		}
+		results[n] = e
+		n++
+	}
+	results = results[:n]
+	sortEnrichment(results)
+	return results, nil
+}
+
+// classic returns the term-by-term over-representation results for the
+// terms annotated to at least one study gene product.
+func (p *enrichmentPopulation) classic(inStudy map[string]bool) []Enrichment {
+	annotated := make(map[int64][]string)
+	terms := make(map[int64]rdf.Term)
+	for g := range inStudy {
+		for _, t := range p.terms[g] {
+			annotated[t.UID] = append(annotated[t.UID], g)
+			terms[t.UID] = t
+		}
+	}
+	N := len(p.genes)
+	n := len(inStudy)
+	results := make([]Enrichment, 0, len(annotated))
+	for uid, genes := range annotated {
+		sort.Strings(genes)
+		k := len(genes)
+		K := p.count[uid]
+		results = append(results, newEnrichment(terms[uid], genes, k, n, K, N, hypergeometricUpper(k, n, K, N)))
+	}
+	return results
+}
+
+// newEnrichment returns an enrichment result for the term t with the given
+// study gene products, counts and p-value.
+func newEnrichment(t rdf.Term, genes []string, k, n, K, N int, p float64) Enrichment {
+	var fold float64
+	if k != 0 {
+		fold = (float64(k) / float64(n)) / (float64(K) / float64(N))
+	}
+	return Enrichment{
+		Term:            t,
+		StudyCount:      k,
+		StudySize:       n,
+		PopulationCount: K,
+		PopulationSize:  N,
+		FoldEnrichment:  fold,
+		P:               p,
+		Genes:           genes,
+	}
+}
+
+// aspect returns the aspect of t, caching the result.
+func (p *enrichmentPopulation) aspect(t rdf.Term) Aspect {
+	a, ok := p.aspects[t.UID]
+	if !ok {
+		a = p.ancestry.Graph.Aspect(t)
+		p.aspects[t.UID] = a
+	}
+	return a
+}
+
+// sortEnrichment sorts results by increasing P and then by term value.
+func sortEnrichment(results []Enrichment) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].P != results[j].P {
+			return results[i].P < results[j].P
+		}
+		return results[i].Term.Value < results[j].Term.Value
+	})
+}
+
+// hypergeometricUpper returns the probability of drawing at least k
+// successes in n draws without replacement from a population of N with
+// K successes.
+func hypergeometricUpper(k, n, K, N int) float64 {
+	hi := n
+	if K < hi {
+		hi = K
+	}
+	lo := n + K - N
+	if lo < 0 {
+		lo = 0
+	}
+	if k <= lo {
+		return 1
+	}
+	if k > hi {
+		return 0
+	}
+	logDenom := logChoose(N, n)
+	var p float64
+	for i := k; i <= hi; i++ {
+		p += math.Exp(logChoose(K, i) + logChoose(N-K, n-i) - logDenom)
+	}
+	return math.Min(p, 1)
+}
+
+// logChoose returns the log of the binomial coefficient n choose k.
+func logChoose(n, k int) float64 {
+	return combin.LogGeneralizedBinomial(float64(n), float64(k))
+}
@@ -0,0 +1,116 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+
+	"github.com/kortschak/gogo"
+)
+
+var enrichmentTests = []struct {
+	name string
+	opts *gogo.EnrichmentOptions
+	want []string
+}{
+	{
+		name: "all",
+		want: []string{
+			"0007154 1/1 1/3 3 0.3333 [g1]",
+			"0007165 1/1 1/3 3 0.3333 [g1]",
+			"0009987 1/1 2/3 1.5 0.6667 [g1]",
+			"0008150 1/1 3/3 1 1 [g1]",
+		},
+	},
+	{
+		name: "min size",
+		opts: &gogo.EnrichmentOptions{MinTermSize: 2},
+		want: []string{
+			"0009987 1/1 2/3 1.5 0.6667 [g1]",
+			"0008150 1/1 3/3 1 1 [g1]",
+		},
+	},
+	{
+		name: "max size",
+		opts: &gogo.EnrichmentOptions{MaxTermSize: 2},
+		want: []string{
+			"0007154 1/1 1/3 3 0.3333 [g1]",
+			"0007165 1/1 1/3 3 0.3333 [g1]",
+			"0009987 1/1 2/3 1.5 0.6667 [g1]",
+		},
+	},
+	{
+		name: "aspect",
+		opts: &gogo.EnrichmentOptions{Aspect: gogo.MolecularFunction},
+		want: nil,
+	},
+}
+
+func TestEnrichment(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		s := annotationStore(t, gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf})
+		for _, test := range enrichmentTests {
+			results, err := s.Enrichment([]string{"g1", "unknown"}, nil, test.opts)
+			if err != nil {
+				t.Fatalf("unexpected error for %s: %v", test.name, err)
+			}
+			var got []string
+			for _, e := range results {
+				id := e.Term.Value[strings.Index(e.Term.Value, "GO_")+3 : len(e.Term.Value)-1]
+				got = append(got, fmt.Sprintf("%s %d/%d %d/%d %.4g %.4g %v",
+					id, e.StudyCount, e.StudySize, e.PopulationCount, e.PopulationSize, e.FoldEnrichment, e.P, e.Genes))
+			}
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected enrichment for %s local=%t:\ngot: %q\nwant:%q", test.name, local, got, test.want)
+			}
+		}
+	}
+}
+
+func TestEnrichmentHypergeometric(t *testing.T) {
+	g := oboGraph(t, ancestryOBO, false)
+	s := gogo.NewAnnotations(gogo.Ancestry{Graph: g})
+	var study, population []string
+	for i := 0; i < 50; i++ {
+		gene := fmt.Sprintf("p%d", i)
+		term := "GO:0008150"
+		if i < 10 {
+			term = "GO:0007165"
+		}
+		err := s.Add(&gogo.Annotation{GeneProduct: gogo.GeneProduct{ID: gene}, TermID: term})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		population = append(population, gene)
+		if i < 5 || (i >= 10 && i < 15) {
+			study = append(study, gene)
+		}
+	}
+
+	// P(X >= 5) for X ~ Hypergeometric(N=50, K=10, n=10).
+	const want = 0.018129475654571375
+	results, err := s.Enrichment(study, population, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, e := range results {
+		if e.Term.Value != goTerm("0007165", false) {
+			continue
+		}
+		if !scalar.EqualWithinAbsOrRel(e.P, want, 1e-12, 1e-12) {
+			t.Errorf("unexpected p-value: got:%v want:%v", e.P, want)
+		}
+		if e.FoldEnrichment != 2.5 {
+			t.Errorf("unexpected fold enrichment: got:%v want:2.5", e.FoldEnrichment)
+		}
+		return
+	}
+	t.Error("missing enrichment result")
+}
@@ -5,9 +5,7 @@
 package gogo_test
 
 import (
-	"compress/gzip"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"strings"
@@ -17,85 +15,61 @@ import (
 	"github.com/kortschak/gogo"
 )
 
-func ExampleQuery_annotation() {
+func ExampleAnnotations_ensembl() {
 	g := gogo.NewGraph()
-	var dec rdf.Decoder
 	// Takes two command line parameters, an N-Triples containing
 	// the SO_transcribed_from predicates of homo_sapiens.ttl and
 	// an N-Triples containing the <rdfs:seeAlso> <obo:GO_*>
 	// statements of homo_sapiens_xrefs.ttl.
 	for _, path := range os.Args[1:] {
-		f, err := os.Open(path)
+		_, err := g.LoadFile(path, nil)
 		if err != nil {
 			log.Fatal(err)
 		}
-		r, err := gzip.NewReader(f)
-		if err != nil {
-			log.Fatal(err)
+	}
+
+	// Ensembl GO annotations are made to transcripts, so
+	// collect the genes each transcript is transcribed from
+	// and the GO terms each transcript refers to.
+	genes := make(map[int64][]rdf.Term)
+	terms := make(map[int64][]rdf.Term)
+	it := g.AllStatements()
+	for it.Next() {
+		s := it.Statement()
+		switch {
+		case s.Predicate.Value == "<obo:SO_transcribed_from>" && strings.HasPrefix(s.Object.Value, "<ensembl:"):
+			// <transcript:Y> <obo:SO_transcribed_from> <ensembl:X> .
+			genes[s.Subject.UID] = append(genes[s.Subject.UID], s.Object)
+		case s.Predicate.Value == "<rdfs:seeAlso>" && strings.HasPrefix(s.Object.Value, "<obo:GO_"):
+			// <transcript:Y> <rdfs:seeAlso> <obo:GO_Z> .
+			terms[s.Subject.UID] = append(terms[s.Subject.UID], s.Object)
 		}
+	}
 
-		dec.Reset(r)
-		var statements []*rdf.Statement
-		for {
-			s, err := dec.Unmarshal()
-			if err != nil {
-				if err != io.EOF {
-					log.Fatalf("error during decoding: %v", err)
+	// Annotate each gene with the GO terms of its transcripts.
+	// The annotation index handles the deduplication of terms
+	// reached through more than one transcript.
+	annotations := gogo.NewAnnotations(gogo.Ancestry{Graph: g})
+	for transcript, gs := range genes {
+		for _, gene := range gs {
+			for _, t := range terms[transcript] {
+				err := annotations.Add(&gogo.Annotation{
+					GeneProduct: gogo.GeneProduct{ID: gene.Value},
+					TermID:      t.Value,
+				})
+				if err != nil {
+					log.Fatal(err)
 				}
-				break
 			}
-
-			s.Subject.UID = 0
-			s.Predicate.UID = 0
-			s.Object.UID = 0
-			statements = append(statements, s)
-		}
-		f.Close()
-
-		for _, s := range statements {
-			g.AddStatement(s)
 		}
 	}
 
-	nodes := g.Nodes()
-	for nodes.Next() {
-		gene := nodes.Node().(rdf.Term)
-		if !strings.HasPrefix(gene.Value, "<ensembl:") {
-			continue
-		}
-
-		// We are emitting directly, so we need to ensure statement
-		// uniqueness. A seen per start node is enough for this. If
-		// we were adding to another graph, the deduplication could
-		// be handled by the destination graph.
-		seen := make(map[int64]bool)
-
-		// Get all GO terms reachable from the ENSG via an ENST
-		// since that is how the Ensembl GO annotation work.
-		terms := g.Query(gene).In(func(s *rdf.Statement) bool {
-			// <transcript:Y> <obo:SO_transcribed_from> <ensembl:X> .
-			return s.Predicate.Value == "<obo:SO_transcribed_from>"
-
-		}).Out(func(s *rdf.Statement) bool {
-			if seen[s.Object.UID] {
-				return false
-			}
-
-			// <transcript:Y> <rdfs:seeAlso> <obo:GO_Z> .
-			ok := s.Predicate.Value == "<rdfs:seeAlso>" &&
-				strings.HasPrefix(s.Object.Value, "<obo:GO_")
-			if ok {
-				seen[s.Object.UID] = true
-			}
-			return ok
-
-		}).Result()
-
-		for _, t := range terms {
+	for _, gene := range annotations.Genes() {
+		for _, t := range annotations.TermsFor(gene) {
 			fmt.Println(&rdf.Statement{
 				Subject:   rdf.Term{Value: t.Value},
 				Predicate: rdf.Term{Value: "<local:annotates>"},
-				Object:    rdf.Term{Value: gene.Value},
+				Object:    rdf.Term{Value: gene},
 			})
 		}
 	}
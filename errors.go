@@ -0,0 +1,82 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Statement rejection classes. A *StatementError reports one of these
+// errors as its Kind and can be tested for with errors.Is.
+var (
+	// ErrInvalidSubject is returned when a statement's subject is
+	// not an IRI or blank node.
+	ErrInvalidSubject = errors.New("subject is not an IRI or blank node")
+
+	// ErrInvalidPredicate is returned when a statement's predicate
+	// is not an IRI.
+	ErrInvalidPredicate = errors.New("predicate is not an IRI")
+
+	// ErrInvalidObject is returned when a statement's object is not
+	// a valid RDF term.
+	ErrInvalidObject = errors.New("object is not a valid term")
+
+	// ErrNamespace is returned when a statement's predicate is not
+	// in the namespace form already used by the graph.
+	ErrNamespace = errors.New("predicate namespace does not match graph")
+
+	// ErrTermCollision is returned when a statement's term UIDs are
+	// not consistent with terms already in the graph.
+	ErrTermCollision = errors.New("term ID collision")
+)
+
+// StatementError is the error type returned when a statement cannot be
+// added to a Graph.
+type StatementError struct {
+	// Statement is the rejected statement.
+	Statement *rdf.Statement
+
+	// Term is the term of the statement that caused
+	// the rejection.
+	Term rdf.Term
+
+	// Kind is the class of the rejection. It is one
+	// of the package's Err variables.
+	Kind error
+
+	// Err is any underlying cause of the rejection.
+	Err error
+}
+
+func (e *StatementError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("gogo: %v: %s", e.Kind, e.Term.Value)
+	}
+	return fmt.Sprintf("gogo: %v: %s: %v", e.Kind, e.Term.Value, e.Err)
+}
+
+// Is returns whether target is the Kind of the receiver.
+func (e *StatementError) Is(target error) bool { return target == e.Kind }
+
+// Unwrap returns the underlying cause of the error.
+func (e *StatementError) Unwrap() error { return e.Err }
+
+// StatementErrors is a collection of statement errors returned by
+// InsertStatements.
+type StatementErrors []*StatementError
+
+func (e StatementErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "gogo: no statements rejected"
+	case 1:
+		return e[0].Error()
+	default:
+		return fmt.Sprintf("%v (and %d more rejected statements)", e[0], len(e)-1)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// GO evidence code groups.
+var (
+	// ExperimentalEvidence is the set of
+	// experimental evidence codes.
+	ExperimentalEvidence = []string{"EXP", "IDA", "IPI", "IMP", "IGI", "IEP"}
+
+	// HighThroughputEvidence is the set of
+	// high throughput experimental evidence
+	// codes.
+	HighThroughputEvidence = []string{"HTP", "HDA", "HMP", "HGI", "HEP"}
+
+	// PhylogeneticEvidence is the set of
+	// phylogenetically inferred evidence codes.
+	PhylogeneticEvidence = []string{"IBA", "IBD", "IKR", "IRD"}
+
+	// ComputationalEvidence is the set of
+	// computational analysis evidence codes.
+	ComputationalEvidence = []string{"ISS", "ISO", "ISA", "ISM", "IGC", "RCA"}
+
+	// AuthorStatementEvidence is the set of
+	// author statement evidence codes.
+	AuthorStatementEvidence = []string{"TAS", "NAS"}
+
+	// CuratorStatementEvidence is the set of
+	// curator statement evidence codes.
+	CuratorStatementEvidence = []string{"IC", "ND"}
+
+	// ElectronicEvidence is the set of
+	// electronic annotation evidence codes.
+	ElectronicEvidence = []string{"IEA"}
+)
+
+// evidenceECO is the default mapping from GO evidence codes to ECO
+// identifiers.
+var evidenceECO = map[string]string{
+	"EXP": "ECO:0000269",
+	"IDA": "ECO:0000314",
+	"IPI": "ECO:0000353",
+	"IMP": "ECO:0000315",
+	"IGI": "ECO:0000316",
+	"IEP": "ECO:0000270",
+	"HTP": "ECO:0006056",
+	"HDA": "ECO:0007005",
+	"HMP": "ECO:0007001",
+	"HGI": "ECO:0007003",
+	"HEP": "ECO:0007007",
+	"IBA": "ECO:0000318",
+	"IBD": "ECO:0000319",
+	"IKR": "ECO:0000320",
+	"IRD": "ECO:0000321",
+	"ISS": "ECO:0000250",
+	"ISO": "ECO:0000266",
+	"ISA": "ECO:0000247",
+	"ISM": "ECO:0000255",
+	"IGC": "ECO:0000317",
+	"RCA": "ECO:0000245",
+	"TAS": "ECO:0000304",
+	"NAS": "ECO:0000303",
+	"IC":  "ECO:0000305",
+	"ND":  "ECO:0000307",
+	"IEA": "ECO:0000501",
+}
+
+// ecoEvidence is the mapping from ECO identifiers to GO evidence codes
+// given by the GO gaf-eco-mapping. It holds the default mapping of each
+// code and the identifiers used with specific GO references, so more than
+// one identifier may map to a code.
+var ecoEvidence = map[string]string{
+	"ECO:0000269": "EXP",
+	"ECO:0000314": "IDA",
+	"ECO:0000353": "IPI",
+	"ECO:0000315": "IMP",
+	"ECO:0000316": "IGI",
+	"ECO:0000270": "IEP",
+	"ECO:0006056": "HTP",
+	"ECO:0007005": "HDA",
+	"ECO:0007001": "HMP",
+	"ECO:0007003": "HGI",
+	"ECO:0007007": "HEP",
+	"ECO:0000318": "IBA",
+	"ECO:0000319": "IBD",
+	"ECO:0000320": "IKR",
+	"ECO:0000321": "IRD",
+	"ECO:0000250": "ISS",
+	"ECO:0000266": "ISO",
+	"ECO:0000247": "ISA",
+	"ECO:0000255": "ISM",
+	"ECO:0000317": "IGC",
+	"ECO:0000245": "RCA",
+	"ECO:0000304": "TAS",
+	"ECO:0000303": "NAS",
+	"ECO:0000305": "IC",
+	"ECO:0000307": "ND",
+	"ECO:0000501": "IEA",
+
+	"ECO:0000203": "IEA",
+	"ECO:0000256": "IEA",
+	"ECO:0000265": "IEA",
+	"ECO:0000322": "IEA",
+	"ECO:0000323": "IEA",
+	"ECO:0000363": "IEA",
+	"ECO:0000364": "IEA",
+	"ECO:0000366": "IEA",
+	"ECO:0007669": "IEA",
+}
+
+// ECO is the Evidence and Conclusion Ontology vocabulary.
+var ECO = Ontology{
+	Prefix: "ECO",
+	Roots: []string{
+		"ECO:0000000", // evidence
+	},
+}
+
+// EvidenceECO returns the ECO identifier for the GO evidence code, for
+// example "ECO:0000314" for "IDA", using the default GO mapping.
+func EvidenceECO(code string) (eco string, ok bool) {
+	eco, ok = evidenceECO[code]
+	return eco, ok
+}
+
+// EvidenceCode returns the GO evidence code for the ECO identifier, for
+// example "IDA" for "ECO:0000314" and "IEA" for "ECO:0007669", using the
+// GO gaf-eco-mapping. ECO identifiers that are not in the mapping are not
+// recognised; the Ancestry HasEvidence method recognises their ECO
+// descendants.
+func EvidenceCode(eco string) (code string, ok bool) {
+	code, ok = ecoEvidence[eco]
+	return code, ok
+}
+
+// evidenceCode returns the GO evidence code of the annotation's evidence,
+// which may be either a GO evidence code or an ECO identifier.
+func (a *Annotation) evidenceCode() string {
+	if strings.HasPrefix(a.Evidence, "ECO:") {
+		code, _ := EvidenceCode(a.Evidence)
+		return code
+	}
+	return a.Evidence
+}
+
+// evidenceECO returns the ECO identifier of the annotation's evidence,
+// which may be either a GO evidence code or an ECO identifier.
+func (a *Annotation) evidenceECO() string {
+	if strings.HasPrefix(a.Evidence, "ECO:") {
+		return a.Evidence
+	}
+	eco, _ := EvidenceECO(a.Evidence)
+	return eco
+}
+
+// HasEvidence returns an annotation filter that accepts annotations with
+// one of the given GO evidence codes. Annotations with ECO identifier
+// evidence are accepted if the GO gaf-eco-mapping maps the identifier to
+// one of the codes.
+func HasEvidence(codes ...string) func(*Annotation) bool {
+	set := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		set[c] = true
+	}
+	return func(a *Annotation) bool {
+		return set[a.evidenceCode()]
+	}
+}
+
+// ExcludeEvidence returns an annotation filter that rejects annotations with
+// one of the given GO evidence codes with the same matching as HasEvidence.
+func ExcludeEvidence(codes ...string) func(*Annotation) bool {
+	has := HasEvidence(codes...)
+	return func(a *Annotation) bool {
+		return !has(a)
+	}
+}
+
+// HasEvidence returns an annotation filter that accepts annotations with
+// one of the given GO evidence codes. ECO identifier evidence is given the
+// GO evidence code of the closest term, the evidence term or one of its
+// ancestors in the graph queried by a, that the GO gaf-eco-mapping maps
+// to a code. The graph must hold the ECO ontology, and ECO is used if
+// a.Ontology is nil. ECO identifiers that are not in the graph are mapped
+// as for the HasEvidence function.
+func (a Ancestry) HasEvidence(codes ...string) func(*Annotation) bool {
+	set := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		set[c] = true
+	}
+	code := a.evidenceCode()
+	return func(ann *Annotation) bool {
+		return set[code(ann)]
+	}
+}
+
+// ExcludeEvidence returns an annotation filter that rejects annotations with
+// one of the given GO evidence codes with the same matching as the Ancestry
+// HasEvidence method.
+func (a Ancestry) ExcludeEvidence(codes ...string) func(*Annotation) bool {
+	has := a.HasEvidence(codes...)
+	return func(ann *Annotation) bool {
+		return !has(ann)
+	}
+}
+
+// evidenceCode returns a function returning the GO evidence code of an
+// annotation's evidence using the ECO ontology in the graph queried by a.
+func (a Ancestry) evidenceCode() func(*Annotation) string {
+	if a.Ontology == nil {
+		eco := ECO
+		a.Ontology = &eco
+	}
+	mapped := make(map[int64]string)
+	for eco, code := range ecoEvidence {
+		t, _, ok := a.Graph.Resolve(eco)
+		if ok {
+			mapped[t.UID] = code
+		}
+	}
+	cache := make(map[string]string)
+	return func(ann *Annotation) string {
+		if !strings.HasPrefix(ann.Evidence, "ECO:") {
+			return ann.Evidence
+		}
+		code, ok := cache[ann.Evidence]
+		if ok {
+			return code
+		}
+		t, _, ok := a.Graph.Resolve(ann.Evidence)
+		if !ok {
+			code = ann.evidenceCode()
+		} else if c, ok := mapped[t.UID]; ok {
+			code = c
+		} else {
+			depth := -1
+			var closest rdf.Term
+			for _, anc := range a.AncestorsOf(t) {
+				c, ok := mapped[anc.Term.UID]
+				if !ok {
+					continue
+				}
+				if depth < 0 || anc.MinDepth < depth || (anc.MinDepth == depth && anc.Term.Value < closest.Value) {
+					depth = anc.MinDepth
+					closest = anc.Term
+					code = c
+				}
+			}
+		}
+		cache[ann.Evidence] = code
+		return code
+	}
+}
+
+// EvidenceWithin returns an annotation filter that accepts annotations with
+// evidence that is one of the given ECO terms or a descendant of one of them
+// in the graph queried by a, which must hold the ECO ontology. The ECO
+// ontology is used if a.Ontology is nil. GO evidence codes are mapped to
+// ECO identifiers using the default GO mapping.
+func (a Ancestry) EvidenceWithin(ids ...string) func(*Annotation) bool {
+	if a.Ontology == nil {
+		eco := ECO
+		a.Ontology = &eco
+	}
+	roots := make(map[int64]bool)
+	for _, id := range ids {
+		t, _, ok := a.Graph.Resolve(id)
+		if ok {
+			roots[t.UID] = true
+		}
+	}
+	cache := make(map[string]bool)
+	return func(ann *Annotation) bool {
+		eco := ann.evidenceECO()
+		if eco == "" {
+			return false
+		}
+		within, ok := cache[eco]
+		if ok {
+			return within
+		}
+		var t rdf.Term
+		t, _, ok = a.Graph.Resolve(eco)
+		if ok {
+			if roots[t.UID] {
+				within = true
+			} else {
+				for _, anc := range a.AncestorsOf(t) {
+					if roots[anc.Term.UID] {
+						within = true
+						break
+					}
+				}
+			}
+		}
+		cache[eco] = within
+		return within
+	}
+}
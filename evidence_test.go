@@ -0,0 +1,182 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kortschak/gogo"
+)
+
+const ecoOBO = `format-version: 1.4
+ontology: eco
+
+[Term]
+id: ECO:0000000
+name: evidence
+
+[Term]
+id: ECO:0000006
+name: experimental evidence
+is_a: ECO:0000000
+
+[Term]
+id: ECO:0000269
+name: experimental evidence used in manual assertion
+is_a: ECO:0000006
+
+[Term]
+id: ECO:0000314
+name: direct assay evidence used in manual assertion
+is_a: ECO:0000269
+
+[Term]
+id: ECO:0005589
+name: specific direct assay evidence used in manual assertion
+is_a: ECO:0000314
+
+[Term]
+id: ECO:0000501
+name: evidence used in automatic assertion
+is_a: ECO:0000000
+
+[Term]
+id: ECO:0007669
+name: computational evidence used in automatic assertion
+is_a: ECO:0000501
+`
+
+var evidenceAnnotations = []struct {
+	gene     string
+	evidence string
+}{
+	{gene: "g1", evidence: "IDA"},
+	{gene: "g2", evidence: "ECO:0000314"},
+	{gene: "g3", evidence: "IEA"},
+	{gene: "g4", evidence: "ECO:0000501"},
+	{gene: "g5", evidence: "ISS"},
+	{gene: "g6", evidence: "ECO:0007669"},
+	{gene: "g7", evidence: "ECO:0005589"},
+}
+
+func TestEvidenceFilter(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		_, err := g.LoadOBO(strings.NewReader(ecoOBO), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s := gogo.NewAnnotations(gogo.Ancestry{Graph: g})
+		for _, a := range evidenceAnnotations {
+			err := s.Add(&gogo.Annotation{
+				GeneProduct: gogo.GeneProduct{ID: a.gene},
+				TermID:      "GO:0007165",
+				Evidence:    a.evidence,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		eco := gogo.Ancestry{Graph: g}
+		for _, test := range []struct {
+			name   string
+			filter func(*gogo.Annotation) bool
+			want   []string
+		}{
+			{
+				name:   "experimental",
+				filter: gogo.HasEvidence(gogo.ExperimentalEvidence...),
+				want:   []string{"g1", "g2"},
+			},
+			{
+				name:   "not electronic",
+				filter: gogo.ExcludeEvidence(gogo.ElectronicEvidence...),
+				want:   []string{"g1", "g2", "g5", "g7"},
+			},
+			{
+				name:   "experimental ontology",
+				filter: eco.HasEvidence(gogo.ExperimentalEvidence...),
+				want:   []string{"g1", "g2", "g7"},
+			},
+			{
+				name:   "direct assay ontology",
+				filter: eco.HasEvidence("IDA"),
+				want:   []string{"g1", "g2", "g7"},
+			},
+			{
+				name:   "inferred from experiment ontology",
+				filter: eco.HasEvidence("EXP"),
+				want:   nil,
+			},
+			{
+				name:   "not electronic ontology",
+				filter: eco.ExcludeEvidence(gogo.ElectronicEvidence...),
+				want:   []string{"g1", "g2", "g5", "g7"},
+			},
+			{
+				name:   "experimental eco",
+				filter: eco.EvidenceWithin("ECO:0000006"),
+				want:   []string{"g1", "g2", "g7"},
+			},
+			{
+				name:   "eco root",
+				filter: eco.EvidenceWithin("ECO:0000000"),
+				want:   []string{"g1", "g2", "g3", "g4", "g6", "g7"},
+			},
+			{
+				name:   "automatic eco",
+				filter: eco.EvidenceWithin("ECO:0000501"),
+				want:   []string{"g3", "g4", "g6"},
+			},
+		} {
+			f := s.Filter(test.filter)
+			got := f.Genes()
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected genes for %s filter local=%t:\ngot: %v\nwant:%v", test.name, local, got, test.want)
+			}
+			term, _ := g.TermFor(goTerm("0008150", local))
+			got = f.PropagatedGenesFor(term)
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected propagated genes for %s filter local=%t:\ngot: %v\nwant:%v", test.name, local, got, test.want)
+			}
+		}
+	}
+}
+
+func TestEvidenceECO(t *testing.T) {
+	for _, code := range [][]string{
+		gogo.ExperimentalEvidence,
+		gogo.HighThroughputEvidence,
+		gogo.PhylogeneticEvidence,
+		gogo.ComputationalEvidence,
+		gogo.AuthorStatementEvidence,
+		gogo.CuratorStatementEvidence,
+		gogo.ElectronicEvidence,
+	} {
+		for _, c := range code {
+			eco, ok := gogo.EvidenceECO(c)
+			if !ok {
+				t.Errorf("no ECO mapping for %s", c)
+				continue
+			}
+			got, ok := gogo.EvidenceCode(eco)
+			if !ok || got != c {
+				t.Errorf("unexpected round trip for %s: got:%s want:%s", c, got, c)
+			}
+		}
+	}
+
+	for _, eco := range []string{
+		"ECO:0000256", "ECO:0000265", "ECO:0000322", "ECO:0000323",
+		"ECO:0000363", "ECO:0000364", "ECO:0000501", "ECO:0007669",
+	} {
+		got, ok := gogo.EvidenceCode(eco)
+		if !ok || got != "IEA" {
+			t.Errorf("unexpected evidence code for %s: got:%s want:IEA", eco, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GAFReader reads annotations from a GO Annotation File (GAF) in the 2.1 or
+// 2.2 format.
+type GAFReader struct {
+	r *tabReader
+	g *Graph
+
+	version string
+	// relations indicates that relation
+	// qualifiers give the annotation's
+	// relation, from GAF 2.2.
+	relations bool
+}
+
+// NewGAFReader returns a GAFReader reading from r. Input compressed with gzip
+// is detected and decompressed. If g is not nil, the term of each annotation
+// is resolved against g. The header lines preceding the first annotation
+// are read by NewGAFReader. If the header does not declare a valid GAF
+// version, the file is read as GAF 2.1.
+func NewGAFReader(r io.Reader, g *Graph) (*GAFReader, error) {
+	tr, err := newTabReader(r, "gaf")
+	if err != nil {
+		return nil, err
+	}
+	version := tr.headerValue("gaf-version")
+	major, minor, ok := parseVersion(version)
+	return &GAFReader{
+		r:         tr,
+		g:         g,
+		version:   version,
+		relations: ok && (major > 2 || (major == 2 && minor >= 2)),
+	}, nil
+}
+
+// parseVersion returns the major and minor numbers of a version string
+// such as "2.2".
+func parseVersion(v string) (major, minor int, ok bool) {
+	i := strings.Index(v, ".")
+	if i < 0 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(v[:i])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(v[i+1:])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// Version returns the GAF version declared in the file header, for example
+// "2.2".
+func (r *GAFReader) Version() string {
+	return r.version
+}
+
+// Header returns the header lines that have been read, without their
+// leading '!'.
+func (r *GAFReader) Header() []string {
+	return r.r.header
+}
+
+// gafRelations is the set of relation names that may appear as GAF 2.2
+// qualifiers.
+var gafRelations = map[string]bool{
+	"enables":                                    true,
+	"contributes_to":                             true,
+	"involved_in":                                true,
+	"acts_upstream_of":                           true,
+	"acts_upstream_of_positive_effect":           true,
+	"acts_upstream_of_negative_effect":           true,
+	"acts_upstream_of_or_within":                 true,
+	"acts_upstream_of_or_within_positive_effect": true,
+	"acts_upstream_of_or_within_negative_effect": true,
+	"located_in":                                 true,
+	"part_of":                                    true,
+	"is_active_in":                               true,
+	"colocalizes_with":                           true,
+}
+
+// Read returns the next annotation in the file. At the end of the input
+// Read returns io.EOF. If a line cannot be parsed, Read returns a
+// *ParseError and subsequent calls continue with the next line.
+func (r *GAFReader) Read() (*Annotation, error) {
+	f, err := r.r.next()
+	if err != nil {
+		return nil, err
+	}
+	if len(f) < 15 || len(f) > 17 {
+		return nil, r.r.errorf("invalid number of columns: %d", len(f))
+	}
+	for len(f) < 17 {
+		f = append(f, "")
+	}
+
+	a := Annotation{
+		GeneProduct: GeneProduct{
+			ID:       f[0] + ":" + f[1],
+			Symbol:   f[2],
+			Name:     f[9],
+			Synonyms: pipeList(f[10]),
+			Type:     f[11],
+			Form:     f[16],
+		},
+		TermID:     f[4],
+		References: pipeList(f[5]),
+		Evidence:   f[6],
+		With:       pipeList(f[7]),
+		AssignedBy: f[14],
+		Extensions: pipeList(f[15]),
+	}
+	if f[0] == "" || f[1] == "" {
+		return nil, r.r.errorf("missing gene product identifier")
+	}
+	if !strings.HasPrefix(a.TermID, "GO:") {
+		return nil, r.r.errorf("invalid GO identifier: %q", a.TermID)
+	}
+
+	for _, q := range pipeList(f[3]) {
+		switch {
+		case q == "NOT":
+			a.Negated = true
+		case gafRelations[q]:
+			a.Qualifiers = append(a.Qualifiers, q)
+			if r.relations {
+				a.Relation = q
+			}
+		default:
+			return nil, r.r.errorf("invalid qualifier: %q", q)
+		}
+	}
+
+	a.Aspect = ParseAspect(f[8])
+	if a.Aspect == UnknownAspect {
+		return nil, r.r.errorf("invalid aspect: %q", f[8])
+	}
+
+	taxa := pipeList(f[12])
+	if len(taxa) == 0 || len(taxa) > 2 {
+		return nil, r.r.errorf("invalid taxon: %q", f[12])
+	}
+	a.GeneProduct.Taxon = taxa[0]
+	if len(taxa) == 2 {
+		a.InteractingTaxon = taxa[1]
+	}
+
+	a.Date, err = time.Parse("20060102", f[13])
+	if err != nil {
+		return nil, r.r.errorf("invalid date: %q", f[13])
+	}
+
+	a.resolveTerm(r.g)
+	return &a, nil
+}
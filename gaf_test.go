@@ -0,0 +1,149 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+const testGAF = `!gaf-version: 2.2
+!generated-by: test
+UniProtKB	P12345	ABC1		GO:0007165	PMID:1|GO_REF:0000001	IDA		P	ABC protein	ABC|ABC-1	protein	taxon:9606	20200102	UniProt	part_of(CL:0000000)	
+UniProtKB	P12345	ABC1	NOT|enables	GO:0003674	PMID:2	IPI	UniProtKB:Q99999,UniProtKB:Q88888|UniProtKB:Q77777	F	ABC protein		protein	taxon:9606|taxon:10090	20200103	UniProt		UniProtKB:P12345-2
+UniProtKB	P12345	ABC1	involved_in	GO:0000004	PMID:3	TAS		P	ABC protein		protein	taxon:9606	20200104	UniProt
+UniProtKB	P12345	ABC1	involved_in	GO:0007165	PMID:3	TAS		X	ABC protein		protein	taxon:9606	20200104	UniProt
+`
+
+func TestGAFReader(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testGAF))
+	w.Close()
+
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, testOBO, local)
+		for _, compressed := range []bool{false, true} {
+			var src io.Reader = strings.NewReader(testGAF)
+			if compressed {
+				src = bytes.NewReader(gz.Bytes())
+			}
+			r, err := gogo.NewGAFReader(src, g)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []*gogo.Annotation
+			var errs []error
+			for {
+				a, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				got = append(got, a)
+			}
+			if r.Version() != "2.2" {
+				t.Errorf("unexpected version: got:%q want:2.2", r.Version())
+			}
+
+			term := func(id string) rdf.Term {
+				t, _ := g.TermFor(goTerm(id, local))
+				return t
+			}
+			want := []*gogo.Annotation{
+				{
+					GeneProduct: gogo.GeneProduct{
+						ID: "UniProtKB:P12345", Symbol: "ABC1", Name: "ABC protein",
+						Synonyms: []string{"ABC", "ABC-1"}, Type: "protein", Taxon: "taxon:9606",
+					},
+					TermID: "GO:0007165", Term: term("0007165"), TermKind: gogo.PrimaryID,
+					References: []string{"PMID:1", "GO_REF:0000001"}, Evidence: "IDA",
+					Aspect: gogo.BiologicalProcess, Date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					AssignedBy: "UniProt", Extensions: []string{"part_of(CL:0000000)"},
+				},
+				{
+					GeneProduct: gogo.GeneProduct{
+						ID: "UniProtKB:P12345", Symbol: "ABC1", Name: "ABC protein",
+						Type: "protein", Taxon: "taxon:9606", Form: "UniProtKB:P12345-2",
+					},
+					Negated: true, Qualifiers: []string{"enables"}, Relation: "enables",
+					TermID: "GO:0003674", Term: term("0003674"), TermKind: gogo.PrimaryID,
+					References: []string{"PMID:2"}, Evidence: "IPI",
+					With:   []string{"UniProtKB:Q99999,UniProtKB:Q88888", "UniProtKB:Q77777"},
+					Aspect: gogo.MolecularFunction, InteractingTaxon: "taxon:10090",
+					Date: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), AssignedBy: "UniProt",
+				},
+				{
+					GeneProduct: gogo.GeneProduct{
+						ID: "UniProtKB:P12345", Symbol: "ABC1", Name: "ABC protein",
+						Type: "protein", Taxon: "taxon:9606",
+					},
+					Qualifiers: []string{"involved_in"}, Relation: "involved_in",
+					TermID: "GO:0000004", Term: term("0008150"), TermKind: gogo.AlternativeID,
+					References: []string{"PMID:3"}, Evidence: "TAS",
+					Aspect: gogo.BiologicalProcess, Date: time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC),
+					AssignedBy: "UniProt",
+				},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected annotations local=%t compressed=%t:\ngot: %#v\nwant:%#v", local, compressed, got, want)
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("unexpected number of errors: got:%d want:1", len(errs))
+			}
+			var perr *gogo.ParseError
+			if !errors.As(errs[0], &perr) || perr.Line != 6 {
+				t.Errorf("unexpected error: %v", errs[0])
+			}
+		}
+	}
+}
+
+var gafVersionTests = []struct {
+	header       string
+	wantVersion  string
+	wantRelation string
+}{
+	{header: "!gaf-version: 2.2\n", wantVersion: "2.2", wantRelation: "involved_in"},
+	{header: "!gaf-version: 2.10\n", wantVersion: "2.10", wantRelation: "involved_in"},
+	{header: "!gaf-version: 2.1\n", wantVersion: "2.1", wantRelation: ""},
+	{header: "!gaf-version:\n", wantVersion: "", wantRelation: ""},
+	{header: "", wantVersion: "", wantRelation: ""},
+}
+
+func TestGAFReaderVersion(t *testing.T) {
+	const row = "UniProtKB\tP12345\tABC1\tinvolved_in\tGO:0007165\tPMID:3\tTAS\t\tP\tABC protein\t\tprotein\ttaxon:9606\t20200104\tUniProt\n"
+	for _, test := range gafVersionTests {
+		r, err := gogo.NewGAFReader(strings.NewReader(test.header+row), nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", test.header, err)
+		}
+		if r.Version() != test.wantVersion {
+			t.Errorf("unexpected version for %q: got:%q want:%q", test.header, r.Version(), test.wantVersion)
+		}
+		a, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", test.header, err)
+		}
+		if a.Relation != test.wantRelation {
+			t.Errorf("unexpected relation for %q: got:%q want:%q", test.header, a.Relation, test.wantRelation)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// GPIReader reads gene products from a Gene Product Information (GPI) 2.0
+// file.
+type GPIReader struct {
+	r *tabReader
+}
+
+// NewGPIReader returns a GPIReader reading from r. Input compressed with gzip
+// is detected and decompressed.
+func NewGPIReader(r io.Reader) (*GPIReader, error) {
+	tr, err := newTabReader(r, "gpi")
+	if err != nil {
+		return nil, err
+	}
+	return &GPIReader{r: tr}, nil
+}
+
+// Version returns the GPI version declared in the file header, for example
+// "2.0".
+func (r *GPIReader) Version() string {
+	return r.r.headerValue("gpi-version")
+}
+
+// Header returns the header lines that have been read, without their
+// leading '!'.
+func (r *GPIReader) Header() []string {
+	return r.r.header
+}
+
+// Read returns the next gene product in the file. At the end of the input
+// Read returns io.EOF. If a line cannot be parsed, Read returns a
+// *ParseError and subsequent calls continue with the next line.
+func (r *GPIReader) Read() (*GeneProduct, error) {
+	f, err := r.r.next()
+	if err != nil {
+		return nil, err
+	}
+	if len(f) < 6 || len(f) > 11 {
+		return nil, r.r.errorf("invalid number of columns: %d", len(f))
+	}
+	for len(f) < 11 {
+		f = append(f, "")
+	}
+	if !isCURIE(f[0]) {
+		return nil, r.r.errorf("invalid gene product identifier: %q", f[0])
+	}
+	return &GeneProduct{
+		ID:         f[0],
+		Symbol:     f[1],
+		Name:       f[2],
+		Synonyms:   pipeList(f[3]),
+		Type:       f[4],
+		Taxon:      f[5],
+		EncodedBy:  pipeList(f[6]),
+		Parent:     f[7],
+		Members:    pipeList(f[8]),
+		Xrefs:      pipeList(f[9]),
+		Properties: pipeList(f[10]),
+	}, nil
+}
+
+// GeneProducts is a set of gene products keyed by their identifiers.
+type GeneProducts map[string]GeneProduct
+
+// ReadGPI returns all the gene products in the GPI 2.0 file read from r.
+// Input compressed with gzip is detected and decompressed. ReadGPI returns
+// the first error encountered.
+func ReadGPI(r io.Reader) (GeneProducts, error) {
+	gr, err := NewGPIReader(r)
+	if err != nil {
+		return nil, err
+	}
+	products := make(GeneProducts)
+	for {
+		p, err := gr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return products, nil
+			}
+			return nil, err
+		}
+		products[p.ID] = *p
+	}
+}
+
+// GPADReader reads annotations from a Gene Product Association Data (GPAD)
+// 2.0 file.
+type GPADReader struct {
+	r        *tabReader
+	g        *Graph
+	products GeneProducts
+}
+
+// NewGPADReader returns a GPADReader reading from r. Input compressed with
+// gzip is detected and decompressed. If g is not nil, the term, relation and
+// evidence of each annotation are resolved against g, and the aspect of the
+// annotation is taken from the resolved term. If products is not nil, the
+// gene product of each annotation is taken from products when it is present.
+func NewGPADReader(r io.Reader, g *Graph, products GeneProducts) (*GPADReader, error) {
+	tr, err := newTabReader(r, "gpad")
+	if err != nil {
+		return nil, err
+	}
+	return &GPADReader{r: tr, g: g, products: products}, nil
+}
+
+// Version returns the GPAD version declared in the file header, for example
+// "2.0".
+func (r *GPADReader) Version() string {
+	return r.r.headerValue("gpad-version")
+}
+
+// Header returns the header lines that have been read, without their
+// leading '!'.
+func (r *GPADReader) Header() []string {
+	return r.r.header
+}
+
+// Read returns the next annotation in the file. At the end of the input
+// Read returns io.EOF. If a line cannot be parsed, Read returns a
+// *ParseError and subsequent calls continue with the next line.
+func (r *GPADReader) Read() (*Annotation, error) {
+	f, err := r.r.next()
+	if err != nil {
+		return nil, err
+	}
+	if len(f) < 10 || len(f) > 12 {
+		return nil, r.r.errorf("invalid number of columns: %d", len(f))
+	}
+	for len(f) < 12 {
+		f = append(f, "")
+	}
+
+	if !isCURIE(f[0]) {
+		return nil, r.r.errorf("invalid gene product identifier: %q", f[0])
+	}
+	product, ok := r.products[f[0]]
+	if !ok {
+		product = GeneProduct{ID: f[0]}
+	}
+
+	var negated bool
+	switch f[1] {
+	case "":
+	case "NOT":
+		negated = true
+	default:
+		return nil, r.r.errorf("invalid negation: %q", f[1])
+	}
+	for _, id := range []string{f[2], f[3], f[5]} {
+		if !isCURIE(id) {
+			return nil, r.r.errorf("invalid identifier: %q", id)
+		}
+	}
+
+	a := Annotation{
+		GeneProduct:      product,
+		Negated:          negated,
+		Relation:         f[2],
+		TermID:           f[3],
+		References:       pipeList(f[4]),
+		Evidence:         f[5],
+		With:             pipeList(f[6]),
+		InteractingTaxon: f[7],
+		AssignedBy:       f[9],
+		Extensions:       pipeList(f[10]),
+		Properties:       pipeList(f[11]),
+	}
+	a.Date, err = time.Parse("2006-01-02", f[8])
+	if err != nil {
+		return nil, r.r.errorf("invalid date: %q", f[8])
+	}
+
+	if r.g != nil {
+		a.resolveTerm(r.g)
+		if a.TermKind != UnknownID {
+			a.Aspect = r.g.Aspect(a.Term)
+		}
+		if t, _, ok := r.g.Resolve(a.Relation); ok {
+			a.RelationTerm = t
+		}
+		if t, _, ok := r.g.Resolve(a.Evidence); ok {
+			a.EvidenceTerm = t
+		}
+	}
+	return &a, nil
+}
+
+// isCURIE returns whether id is a prefixed identifier such as "GO:0008150".
+func isCURIE(id string) bool {
+	i := strings.Index(id, ":")
+	return i > 0 && i < len(id)-1 && !strings.ContainsAny(id, " \t|")
+}
@@ -0,0 +1,209 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+const testGPI = `!gpi-version: 2.0
+UniProtKB:P12345	ABC1	ABC protein	ABC|ABC-1	PR:000000001	NCBITaxon:9606	HGNC:1			HGNC:1|Ensembl:ENSG1	db_subset=Swiss-Prot
+UniProtKB:P12345-2	ABC1	ABC protein isoform 2		PR:000000001	NCBITaxon:9606		UniProtKB:P12345
+P99999	bad	bad		PR:000000001	NCBITaxon:9606
+`
+
+const testGPAD = `!gpad-version: 2.0
+UniProtKB:P12345		RO:0002327	GO:0003674	PMID:1	ECO:0000314		NCBITaxon:10090	2020-01-02	UniProt	occurs_in(CL:0000000)	contributor-id=orcid:0000
+UniProtKB:P12345-2	NOT	RO:0002331	GO:0000004	PMID:2|PMID:3	ECO:0000353	UniProtKB:Q99999		2020-01-03	UniProt
+UniProtKB:P00000		RO:0002432	GO:0005575	PMID:4	ECO:0000314		
+UniProtKB:P00000	MAYBE	RO:0002432	GO:0005575	PMID:4	ECO:0000314			2020-01-03	UniProt
+`
+
+const gpadOBO = `format-version: 1.4
+ontology: eco
+
+[Term]
+id: ECO:0000314
+name: direct assay evidence used in manual assertion
+
+[Typedef]
+id: RO:0002327
+name: enables
+`
+
+func TestReadGPI(t *testing.T) {
+	products, err := gogo.ReadGPI(strings.NewReader(testGPI))
+	var perr *gogo.ParseError
+	if !errors.As(err, &perr) || perr.Line != 4 {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	r, err := gogo.NewGPIReader(strings.NewReader(testGPI))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []*gogo.GeneProduct
+	for {
+		p, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		got = append(got, p)
+	}
+	if r.Version() != "2.0" {
+		t.Errorf("unexpected version: got:%q want:2.0", r.Version())
+	}
+	want := []*gogo.GeneProduct{
+		{
+			ID: "UniProtKB:P12345", Symbol: "ABC1", Name: "ABC protein",
+			Synonyms: []string{"ABC", "ABC-1"}, Type: "PR:000000001", Taxon: "NCBITaxon:9606",
+			EncodedBy: []string{"HGNC:1"}, Xrefs: []string{"HGNC:1", "Ensembl:ENSG1"},
+			Properties: []string{"db_subset=Swiss-Prot"},
+		},
+		{
+			ID: "UniProtKB:P12345-2", Symbol: "ABC1", Name: "ABC protein isoform 2",
+			Type: "PR:000000001", Taxon: "NCBITaxon:9606", Parent: "UniProtKB:P12345",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected gene products:\ngot: %#v\nwant:%#v", got, want)
+	}
+	if products != nil {
+		t.Errorf("unexpected gene products with error: %v", products)
+	}
+}
+
+func TestGPADReader(t *testing.T) {
+	products := gogo.GeneProducts{}
+	r, err := gogo.NewGPIReader(strings.NewReader(testGPI))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		p, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			products[p.ID] = *p
+		}
+	}
+
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, testOBO, local)
+		_, err := g.LoadOBO(strings.NewReader(gpadOBO), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		term := func(iri string) rdf.Term {
+			if local {
+				iri = "<obo:" + iri + ">"
+			} else {
+				iri = "<http://purl.obolibrary.org/obo/" + iri + ">"
+			}
+			t, _ := g.TermFor(iri)
+			return t
+		}
+
+		r, err := gogo.NewGPADReader(strings.NewReader(testGPAD), g, products)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []*gogo.Annotation
+		var lines []int
+		for {
+			a, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var perr *gogo.ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("unexpected error type: %T", err)
+				}
+				lines = append(lines, perr.Line)
+				continue
+			}
+			got = append(got, a)
+		}
+
+		want := []*gogo.Annotation{
+			{
+				GeneProduct: products["UniProtKB:P12345"],
+				Relation:    "RO:0002327", RelationTerm: term("RO_0002327"),
+				TermID: "GO:0003674", Term: term("GO_0003674"), TermKind: gogo.PrimaryID,
+				References: []string{"PMID:1"},
+				Evidence:   "ECO:0000314", EvidenceTerm: term("ECO_0000314"),
+				Aspect: gogo.MolecularFunction, InteractingTaxon: "NCBITaxon:10090",
+				Date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), AssignedBy: "UniProt",
+				Extensions: []string{"occurs_in(CL:0000000)"},
+				Properties: []string{"contributor-id=orcid:0000"},
+			},
+			{
+				GeneProduct: products["UniProtKB:P12345-2"],
+				Negated:     true,
+				Relation:    "RO:0002331",
+				TermID:      "GO:0000004", Term: term("GO_0008150"), TermKind: gogo.AlternativeID,
+				References: []string{"PMID:2", "PMID:3"},
+				Evidence:   "ECO:0000353",
+				With:       []string{"UniProtKB:Q99999"},
+				Aspect:     gogo.BiologicalProcess,
+				Date:       time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), AssignedBy: "UniProt",
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected annotations local=%t:\ngot: %#v\nwant:%#v", local, got, want)
+		}
+		if !reflect.DeepEqual(lines, []int{4, 5}) {
+			t.Errorf("unexpected error lines: got:%v want:[4 5]", lines)
+		}
+	}
+}
+
+const electronicGPAD = `!gpad-version: 2.0
+UniProtKB:P12345		RO:0002327	GO:0003674	GO_REF:0000002	ECO:0000256		NCBITaxon:9606	2020-01-02	InterPro
+UniProtKB:P12345		RO:0002327	GO:0003674	GO_REF:0000117	ECO:0007669		NCBITaxon:9606	2020-01-02	ARBA
+UniProtKB:P67890		RO:0002327	GO:0003674	PMID:1	ECO:0000314		NCBITaxon:9606	2020-01-02	UniProt
+`
+
+func TestGPADElectronicEvidence(t *testing.T) {
+	g := oboGraph(t, testOBO, false)
+	r, err := gogo.NewGPADReader(strings.NewReader(electronicGPAD), g, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	electronic := gogo.HasEvidence(gogo.ElectronicEvidence...)
+	notElectronic := gogo.ExcludeEvidence(gogo.ElectronicEvidence...)
+	var got []bool
+	for {
+		a, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if electronic(a) == notElectronic(a) {
+			t.Errorf("inconsistent evidence filters for %s", a.Evidence)
+		}
+		got = append(got, electronic(a))
+	}
+	want := []bool{true, true, false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected electronic evidence: got:%v want:%v", got, want)
+	}
+}
@@ -9,6 +9,7 @@
 package gogo
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -31,11 +32,13 @@ type Graph struct {
 	ids     *uid.Set
 
 	namespace int
+	prefixes  *prefixMapper
+	ontology  *Ontology
 }
 
 const (
-	local   = iota - 1
-	unknown //nolint:deadcode,unused,varcheck
+	local = iota - 1
+	unknown
 	global
 )
 
@@ -71,25 +74,99 @@ func (g *Graph) addNode(n graph.Node) {
 // otherwise AddStatement will panic. Predicate IRIs must either all be
 // globally namespaced (prefixed with the http scheme) or all use the qualified
 // name prefix, otherwise AddStatement will panic. Subject and object IRIs
-// should match.
+// should match. If the graph has been configured to normalise statements by
+// the Normalize method, the IRIs of s are rewritten into the graph's form
+// before s is checked and added.
+//
+// The value passed to panic is a *StatementError. InsertStatement may be
+// used to add statements without panicking.
 func (g *Graph) AddStatement(s *rdf.Statement) {
+	err := g.InsertStatement(s)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// InsertStatement adds s to the graph with the same semantics as AddStatement,
+// but returns a *StatementError describing the failure instead of panicking
+// when s cannot be added. If an error is returned, the graph and s are left
+// unaltered.
+func (g *Graph) InsertStatement(s *rdf.Statement) error {
+	n := s
+	if g.prefixes != nil {
+		n = g.normalize(s)
+	}
+	namespace, err := g.check(n)
+	if err != nil {
+		return err
+	}
+	g.namespace = namespace
+	if n != s {
+		s.Subject, s.Predicate, s.Object = n.Subject, n.Predicate, n.Object
+	}
+
+	// Terms must be added before the predicate index
+	// is updated so that zero UIDs have been assigned.
+	g.addTerm(&s.Subject)
+	g.addTerm(&s.Predicate)
+	g.addTerm(&s.Object)
+	statements, ok := g.pred[s.Predicate.UID]
+	if !ok {
+		statements = make(map[*rdf.Statement]bool)
+		g.pred[s.Predicate.UID] = statements
+	}
+	statements[s] = true
+	g.setLine(s)
+	return nil
+}
+
+// InsertStatements adds the statements to the graph, returning the number of
+// statements that were added. If collect is false, insertion stops at the first
+// statement that cannot be added and its *StatementError is returned. If collect
+// is true, statements that cannot be added are skipped and the returned error
+// is a StatementErrors holding a *StatementError for each rejected statement.
+func (g *Graph) InsertStatements(statements []*rdf.Statement, collect bool) (n int, err error) {
+	var errs StatementErrors
+	for _, s := range statements {
+		err := g.InsertStatement(s)
+		if err != nil {
+			if !collect {
+				return n, err
+			}
+			errs = append(errs, err.(*StatementError))
+			continue
+		}
+		n++
+	}
+	if len(errs) != 0 {
+		return n, errs
+	}
+	return n, nil
+}
+
+// check returns the namespace the graph will have if s is added to it, and
+// a *StatementError if s cannot be added.
+func (g *Graph) check(s *rdf.Statement) (namespace int, err error) {
+	namespace = g.namespace
 	text, _, kind, err := s.Predicate.Parts()
 	if err != nil {
-		panic(fmt.Errorf("gogo: error extracting predicate: %w", err))
+		return namespace, &StatementError{Statement: s, Term: s.Predicate, Kind: ErrInvalidPredicate, Err: err}
 	}
 	if kind != rdf.IRI {
-		panic(fmt.Errorf("gogo: predicate is not an IRI: %s", s.Predicate.Value))
+		return namespace, &StatementError{Statement: s, Term: s.Predicate, Kind: ErrInvalidPredicate}
 	}
 	if strings.HasPrefix(text, "http:") {
-		if g.namespace == local {
-			panic(fmt.Errorf("gogo: adding predicate with global IRI to locally namespaced graph: %s", s.Predicate.Value))
+		if namespace == local {
+			return namespace, &StatementError{Statement: s, Term: s.Predicate, Kind: ErrNamespace,
+				Err: errors.New("adding predicate with global IRI to locally namespaced graph")}
 		}
-		g.namespace = global
+		namespace = global
 	} else {
-		if g.namespace == global {
-			panic(fmt.Errorf("gogo: adding predicate with local IRI to globally namespaced graph: %s", s.Predicate.Value))
+		if namespace == global {
+			return namespace, &StatementError{Statement: s, Term: s.Predicate, Kind: ErrNamespace,
+				Err: errors.New("adding predicate with local IRI to globally namespaced graph")}
 		}
-		g.namespace = local
+		namespace = local
 	}
 
 	// The http URI subject and objects in the owl:Ontology prevent us
@@ -98,32 +175,64 @@ func (g *Graph) AddStatement(s *rdf.Statement) {
 
 	_, _, kind, err = s.Subject.Parts()
 	if err != nil {
-		panic(fmt.Errorf("gogo: error extracting subject: %w", err))
+		return namespace, &StatementError{Statement: s, Term: s.Subject, Kind: ErrInvalidSubject, Err: err}
 	}
 	switch kind {
 	case rdf.IRI, rdf.Blank:
 	default:
-		panic(fmt.Errorf("gogo: subject is not an IRI or blank node: %s", s.Subject.Value))
+		return namespace, &StatementError{Statement: s, Term: s.Subject, Kind: ErrInvalidSubject}
 	}
 
 	_, _, kind, err = s.Object.Parts()
 	if err != nil {
-		panic(fmt.Errorf("gogo: error extracting object: %w", err))
+		return namespace, &StatementError{Statement: s, Term: s.Object, Kind: ErrInvalidObject, Err: err}
 	}
 	if kind == rdf.Invalid {
-		panic(fmt.Errorf("gogo: object is not a valid term: %s", s.Object.Value))
+		return namespace, &StatementError{Statement: s, Term: s.Object, Kind: ErrInvalidObject}
 	}
 
-	statements, ok := g.pred[s.Predicate.UID]
-	if !ok {
-		statements = make(map[*rdf.Statement]bool)
-		g.pred[s.Predicate.UID] = statements
+	// Check that the terms' UIDs are consistent with the graph
+	// and with each other.
+	terms := [...]rdf.Term{s.Subject, s.Predicate, s.Object}
+	for i, t := range terms {
+		if t.UID == 0 {
+			continue
+		}
+		if id, ok := g.termIDs[t.Value]; ok && id != t.UID {
+			return namespace, &StatementError{Statement: s, Term: t, Kind: ErrTermCollision,
+				Err: fmt.Errorf("new ID:%d old ID:%d", t.UID, id)}
+		}
+		if v, ok := g.termValue(t.UID); ok && v != t.Value {
+			return namespace, &StatementError{Statement: s, Term: t, Kind: ErrTermCollision,
+				Err: fmt.Errorf("ID:%d already used by %s", t.UID, v)}
+		}
+		for _, o := range terms[:i] {
+			if o.UID == 0 {
+				continue
+			}
+			if (o.Value == t.Value) != (o.UID == t.UID) {
+				return namespace, &StatementError{Statement: s, Term: t, Kind: ErrTermCollision,
+					Err: fmt.Errorf("inconsistent with %s ID:%d", o.Value, o.UID)}
+			}
+		}
 	}
-	statements[s] = true
-	g.addTerm(&s.Subject)
-	g.addTerm(&s.Predicate)
-	g.addTerm(&s.Object)
-	g.setLine(s)
+
+	return namespace, nil
+}
+
+// termValue returns the value of the term with the given UID if it exists in
+// the graph as a node or predicate.
+func (g *Graph) termValue(id int64) (value string, ok bool) {
+	if n, ok := g.nodes[id]; ok {
+		if t, ok := n.(rdf.Term); ok {
+			return t.Value, true
+		}
+		return "", false
+	}
+	for s := range g.pred[id] {
+		return s.Predicate.Value, true
+	}
+	return "", false
 }
 
 // addTerm adds t to the graph. It panics if the added node ID matches an existing node ID.
@@ -155,94 +264,57 @@ func (g *Graph) AllStatements() *Statements {
 }
 
 // ClosestCommonAncestor returns the term that is the closest common ancestor
-// of a and b if it exists in g.
+// of a and b if it exists in g. Only is_a relations are followed; Ancestry
+// provides ancestry queries that follow other relations.
 func (g *Graph) ClosestCommonAncestor(a, b rdf.Term) (r rdf.Term, ok bool) {
-	var goTerm, subClassOf string
-	switch g.namespace {
-	case local:
-		goTerm = "<obo:GO_"
-		subClassOf = "<rdfs:subClassOf>"
-	case global:
-		goTerm = "<http://purl.obolibrary.org/obo/GO_"
-		subClassOf = "<http://www.w3.org/2000/01/rdf-schema#subClassOf>"
-	default:
-		return
-	}
-	if !strings.HasPrefix(a.Value, goTerm) || !strings.HasPrefix(b.Value, goTerm) {
-		return
-	}
-
-	if a == b {
-		return a, true
-	}
+	return Ancestry{Graph: g}.ClosestCommonAncestor(a, b)
+}
 
-	seen := make(map[int64]bool)
-	var bf traverse.BreadthFirst
-	bf.Traverse = func(e graph.Edge) bool {
-		return ConnectedByAny(e, func(s *rdf.Statement) bool {
-			return strings.HasPrefix(s.Object.Value, goTerm) && s.Predicate.Value == subClassOf
-		})
-	}
-	bf.Walk(g, a, func(n graph.Node, d int) bool {
-		seen[n.ID()] = true
-		return false
-	})
-	bf.Reset()
-	bf.Walk(g, b, func(n graph.Node, d int) bool {
-		if seen[n.ID()] {
-			r = n.(rdf.Term)
-			ok = true
-			return true
-		}
-		return false
-	})
-	return r, ok
+// LowestCommonAncestors returns the lowest common ancestors of a and b in g,
+// sorted by term value. Only is_a relations are followed; Ancestry provides
+// ancestry queries that follow other relations.
+func (g *Graph) LowestCommonAncestors(a, b rdf.Term) []rdf.Term {
+	return Ancestry{Graph: g}.LowestCommonAncestors(a, b)
 }
 
-// DescendantsOf returns all of the descendants of the given term.
+// DescendantsOf returns all of the descendants of the given term. Only is_a
+// relations are followed; Ancestry provides ancestry queries that follow
+// other relations.
 func (g *Graph) DescendantsOf(t rdf.Term) []Descendant {
-	var goTerm, subClassOf string
-	switch g.namespace {
-	case local:
-		goTerm = "<obo:GO_"
-		subClassOf = "<rdfs:subClassOf>"
-	case global:
-		goTerm = "<http://purl.obolibrary.org/obo/GO_"
-		subClassOf = "<http://www.w3.org/2000/01/rdf-schema#subClassOf>"
-	default:
-		return nil
-	}
-	if !strings.HasPrefix(t.Value, goTerm) {
-		return nil
-	}
-	var desc []Descendant
-	var bf traverse.BreadthFirst
-	bf.Traverse = func(e graph.Edge) bool {
-		return ConnectedByAny(e, func(s *rdf.Statement) bool {
-			return strings.HasPrefix(s.Subject.Value, goTerm) && s.Predicate.Value == subClassOf
-		})
-	}
-	bf.Walk(reverse{g}, t, func(n graph.Node, d int) bool {
-		if n != t {
-			desc = append(desc, Descendant{Term: n.(rdf.Term), Depth: d})
-		}
-		return false
-	})
-	return desc
+	return Ancestry{Graph: g}.DescendantsOf(t)
 }
 
-// reverse implements the traverse.Graph reversing the direction of edges.
-type reverse struct {
-	*Graph
+// Descendant represents a descendancy relationship.
+type Descendant struct {
+	Term rdf.Term
+
+	// MinDepth and MaxDepth are the shortest and
+	// longest path lengths to the descendant.
+	MinDepth, MaxDepth int
+
+	// Relations is the set of relations on
+	// any path to the descendant.
+	Relations []Relation
+}
+
+// AncestorsOf returns all of the ancestors of the given term. Only is_a
+// relations are followed; Ancestry provides ancestry queries that follow
+// other relations.
+func (g *Graph) AncestorsOf(t rdf.Term) []Ancestor {
+	return Ancestry{Graph: g}.AncestorsOf(t)
 }
 
-func (g reverse) From(id int64) graph.Nodes      { return g.Graph.To(id) }
-func (g reverse) Edge(uid, vid int64) graph.Edge { return g.Graph.Edge(vid, uid) }
+// Ancestor represents an ancestry relationship.
+type Ancestor struct {
+	Term rdf.Term
 
-// Descendant represents a descendancy relationship.
-type Descendant struct {
-	Term  rdf.Term
-	Depth int
+	// MinDepth and MaxDepth are the shortest and
+	// longest path lengths to the ancestor.
+	MinDepth, MaxDepth int
+
+	// Relations is the set of relations on
+	// any path to the ancestor.
+	Relations []Relation
 }
 
 // Edge returns the edge from u to v if such an edge exists and nil otherwise.
@@ -322,41 +394,10 @@ func (g *Graph) HasEdgeFromTo(uid, vid int64) bool {
 
 // IsDescendantOf returns whether the query q is a descendant of a and how
 // many levels separate them if it is. If q is not a descendant of a, depth
-// will be negative.
+// will be negative. Only is_a relations are followed; Ancestry provides
+// ancestry queries that follow other relations.
 func (g *Graph) IsDescendantOf(a, q rdf.Term) (yes bool, depth int) {
-	depth = -1
-	var goTerm, subClassOf string
-	switch g.namespace {
-	case local:
-		goTerm = "<obo:GO_"
-		subClassOf = "<rdfs:subClassOf>"
-	case global:
-		goTerm = "<http://purl.obolibrary.org/obo/GO_"
-		subClassOf = "<http://www.w3.org/2000/01/rdf-schema#subClassOf>"
-	default:
-		return
-	}
-	if !strings.HasPrefix(a.Value, goTerm) {
-		return
-	}
-	if !strings.HasPrefix(q.Value, goTerm) {
-		return
-	}
-	var bf traverse.BreadthFirst
-	bf.Traverse = func(e graph.Edge) bool {
-		return ConnectedByAny(e, func(s *rdf.Statement) bool {
-			return strings.HasPrefix(s.Object.Value, goTerm) && s.Predicate.Value == subClassOf
-		})
-	}
-	bf.Walk(g, q, func(n graph.Node, d int) bool {
-		if n == a {
-			yes = true
-			depth = d
-			return true
-		}
-		return false
-	})
-	return yes, depth
+	return Ancestry{Graph: g}.IsDescendantOf(a, q)
 }
 
 // Lines returns the lines from u to v if such any such lines exists and nil otherwise.
@@ -559,43 +600,24 @@ func (g *Graph) RemoveTerm(t rdf.Term) {
 }
 
 // Roots returns all the roots of the graph. It will first attempt to find
-// roots from the three known roots molecular_function, cellular_component
-// and biological_process in the appropriate namespace and if none can be
-// found, will search from all GO terms for the complete set of roots. If
-// force is true, a complete search will be done.
+// roots from the known roots of the graph's ontology, for GO these are
+// molecular_function, cellular_component and biological_process, in the
+// appropriate namespace and if none can be found, will search from all
+// ontology terms for the complete set of roots. If force is true, a complete
+// search will be done.
 func (g *Graph) Roots(force bool) []rdf.Term {
-	var goTerm, subClassOf, deprecated, w3True string
-	var standardRoots []string
-	switch g.namespace {
-	case local:
-		goTerm = "<obo:GO_"
-		subClassOf = "<rdfs:subClassOf>"
-		deprecated = "<owl:deprecated>"
-		w3True = `"true"^^<xsd:boolean>`
-		standardRoots = []string{
-			"<obo:GO_0003674>", // molecular_function
-			"<obo:GO_0005575>", // cellular_component
-			"<obo:GO_0008150>", // biological_process
-		}
-	case global:
-		goTerm = "<http://purl.obolibrary.org/obo/GO_"
-		subClassOf = "<http://www.w3.org/2000/01/rdf-schema#subClassOf>"
-		deprecated = "<http://www.w3.org/2002/07/owl#deprecated>"
-		w3True = `"true"^^<http://www.w3.org/2001/XMLSchema#boolean>`
-		standardRoots = []string{
-			"<http://purl.obolibrary.org/obo/GO_0003674>", // molecular_function
-			"<http://purl.obolibrary.org/obo/GO_0005575>", // cellular_component
-			"<http://purl.obolibrary.org/obo/GO_0008150>", // biological_process
-		}
-	default:
+	o := g.Ontology()
+	termPrefix, ok := o.termPrefix(g.namespace)
+	if !ok {
 		return nil
 	}
+	subClassOf := iriTerm(g.namespace, o.subClassOf())
 
 	rootSet := make(map[rdf.Term]bool)
 
 	// First check for standard roots.
-	for _, r := range standardRoots {
-		if t, ok := g.TermFor(r); ok {
+	for _, r := range o.Roots {
+		if t, ok := g.TermFor(iriTerm(g.namespace, oboQName(r, ""))); ok {
 			rootSet[t] = true
 		}
 	}
@@ -610,25 +632,22 @@ func (g *Graph) Roots(force bool) []rdf.Term {
 			var df traverse.DepthFirst
 			df.Traverse = func(e graph.Edge) bool {
 				return ConnectedByAny(e, func(s *rdf.Statement) bool {
-					return strings.HasPrefix(s.Object.Value, goTerm) && s.Predicate.Value == subClassOf
+					return strings.HasPrefix(s.Object.Value, termPrefix) && s.Predicate.Value == subClassOf
 				})
 			}
 			final := df.Walk(g, t, func(n graph.Node) bool {
 				t := n.(rdf.Term)
-				if !strings.HasPrefix(t.Value, goTerm) {
+				if !strings.HasPrefix(t.Value, termPrefix) {
 					return false
 				}
 				// Ignore deprecated terms since they may be dead ends.
-				dep := g.Query(t).Out(func(s *rdf.Statement) bool {
-					return s.Predicate.Value == deprecated && s.Object.Value == w3True
-				})
-				if len(dep.Result()) != 0 {
+				if g.isObsolete(t, o) {
 					return false
 				}
 
 				// If we can reach another subclass, we are not done yet.
 				more := g.Query(t).Out(func(s *rdf.Statement) bool {
-					return strings.HasPrefix(s.Object.Value, goTerm) && s.Predicate.Value == subClassOf
+					return strings.HasPrefix(s.Object.Value, termPrefix) && s.Predicate.Value == subClassOf
 				})
 				return len(more.Result()) == 0
 			})
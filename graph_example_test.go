@@ -5,53 +5,27 @@
 package gogo_test
 
 import (
-	"compress/gzip"
 	"fmt"
-	"io"
 	"log"
-	"os"
-
-	"gonum.org/v1/gonum/graph/formats/rdf"
 
 	"github.com/kortschak/gogo"
 )
 
 func ExampleGraph() {
-	f, err := os.Open("path/to/go.nt.gz")
-	if err != nil {
-		log.Fatal(err)
-	}
-	r, err := gzip.NewReader(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	dec := rdf.NewDecoder(r)
-	var statements []*rdf.Statement
-	for {
-		s, err := dec.Unmarshal()
-		if err != nil {
-			if err != io.EOF {
-				log.Fatalf("error during decoding: %v", err)
-			}
-			break
-		}
-
-		// Statements can be filtered at this point to exclude unwanted
-		// or irrelevant parts of the graph.
-		statements = append(statements, s)
-	}
-	f.Close()
-
-	// Canonicalise blank nodes to reduce memory footprint.
-	statements, err = rdf.URDNA2015(statements, statements)
+	g := gogo.NewGraph()
+	stats, err := g.LoadFile("path/to/go.nt.gz", &gogo.LoadOptions{
+		// Canonicalise blank nodes to reduce memory footprint.
+		Canonicalize: true,
+
+		// Statements can be filtered at this point with a Filter
+		// function to exclude unwanted or irrelevant parts of the
+		// graph.
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	g := gogo.NewGraph()
-	for _, s := range statements {
-		g.AddStatement(s)
+	for _, err := range stats.Errors {
+		log.Printf("rejected statement: %v", err)
 	}
 
 	// Do something with the graph.
@@ -60,38 +34,11 @@ func ExampleGraph() {
 var g *gogo.Graph
 
 func init() {
-	f, err := os.Open("testdata/go.nt.gz")
-	if err != nil {
-		log.Fatal(err)
-	}
-	r, err := gzip.NewReader(f)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	dec := rdf.NewDecoder(r)
-	var statements []*rdf.Statement
-	for {
-		s, err := dec.Unmarshal()
-		if err != nil {
-			if err != io.EOF {
-				log.Fatalf("error during decoding: %v", err)
-			}
-			break
-		}
-		statements = append(statements, s)
-	}
-	f.Close()
-
-	statements, err = rdf.URDNA2015(statements, statements)
+	g = gogo.NewGraph()
+	_, err := g.LoadFile("testdata/go.nt.gz", &gogo.LoadOptions{Canonicalize: true})
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	g = gogo.NewGraph()
-	for _, s := range statements {
-		g.AddStatement(s)
-	}
 }
 
 func ExampleGraph_Roots() {
@@ -184,7 +131,7 @@ func ExampleGraph_DescendantsOf() {
 	}
 
 	for _, d := range g.DescendantsOf(a) {
-		fmt.Printf("%s %d\n", d.Term.Value, d.Depth)
+		fmt.Printf("%s %d\n", d.Term.Value, d.MinDepth)
 	}
 
 	// Unordered output:
@@ -10,8 +10,10 @@ package gogo_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"math"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -378,6 +380,201 @@ func TestRemoveTerm(t *testing.T) {
 	}
 }
 
+var insertStatementTests = []struct {
+	name    string
+	triples string
+	add     []*rdf.Statement
+	want    []error
+}{
+	{
+		name: "literal predicate",
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: `"p"`}, Object: rdf.Term{Value: "<ex:b>"}},
+		},
+		want: []error{gogo.ErrInvalidPredicate},
+	},
+	{
+		name: "literal subject",
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: `"a"`}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:b>"}},
+		},
+		want: []error{gogo.ErrInvalidSubject},
+	},
+	{
+		name: "invalid object",
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "ex:b"}},
+		},
+		want: []error{gogo.ErrInvalidObject},
+	},
+	{
+		name: "mixed namespace",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+`,
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: "<http://example.com/p>"}, Object: rdf.Term{Value: "<ex:b>"}},
+		},
+		want: []error{gogo.ErrNamespace},
+	},
+	{
+		name: "term collision",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+`,
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>", UID: 1000}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:c>"}},
+		},
+		want: []error{gogo.ErrTermCollision},
+	},
+	{
+		name: "internal collision",
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>", UID: 1}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:b>", UID: 1}},
+		},
+		want: []error{gogo.ErrTermCollision},
+	},
+	{
+		name: "mixed validity",
+		add: []*rdf.Statement{
+			{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:b>"}},
+			{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: `"p"`}, Object: rdf.Term{Value: "<ex:b>"}},
+			{Subject: rdf.Term{Value: "<ex:b>"}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:c>"}},
+			{Subject: rdf.Term{Value: "<ex:c>"}, Predicate: rdf.Term{Value: "<http://example.com/p>"}, Object: rdf.Term{Value: "<ex:a>"}},
+		},
+		want: []error{nil, gogo.ErrInvalidPredicate, nil, gogo.ErrNamespace},
+	},
+}
+
+func TestInsertStatement(t *testing.T) {
+	for _, test := range insertStatementTests {
+		g, _, err := graphFromReader(strings.NewReader(test.triples))
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.name, err)
+		}
+		var before []string
+		it := g.AllStatements()
+		for it.Next() {
+			before = append(before, it.Statement().String())
+		}
+		sort.Strings(before)
+
+		var added int
+		for i, s := range test.add {
+			s := *s
+			err := g.InsertStatement(&s)
+			if !errors.Is(err, test.want[i]) || (err == nil) != (test.want[i] == nil) {
+				t.Errorf("unexpected error for %q statement %d: got:%v want:%v", test.name, i, err, test.want[i])
+			}
+			if err == nil {
+				added++
+				continue
+			}
+			var serr *gogo.StatementError
+			if !errors.As(err, &serr) {
+				t.Errorf("unexpected error type for %q statement %d: %T", test.name, i, err)
+			}
+		}
+
+		var after []string
+		it = g.AllStatements()
+		for it.Next() {
+			after = append(after, it.Statement().String())
+		}
+		if len(after) != len(before)+added {
+			t.Errorf("unexpected number of statements for %q: got:%d want:%d", test.name, len(after), len(before)+added)
+		}
+	}
+}
+
+func TestInsertStatementPredicateIndex(t *testing.T) {
+	g := gogo.NewGraph()
+	statements := []*rdf.Statement{
+		{Subject: rdf.Term{Value: "<ex:a>"}, Predicate: rdf.Term{Value: "<ex:p>"}, Object: rdf.Term{Value: "<ex:b>"}},
+		{Subject: rdf.Term{Value: "<ex:b>"}, Predicate: rdf.Term{Value: "<ex:q>"}, Object: rdf.Term{Value: "<ex:c>"}},
+	}
+	for _, s := range statements {
+		err := g.InsertStatement(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var preds []string
+	for _, p := range g.Predicates() {
+		preds = append(preds, p.Value)
+	}
+	sort.Strings(preds)
+	if want := []string{"<ex:p>", "<ex:q>"}; !reflect.DeepEqual(preds, want) {
+		t.Errorf("unexpected predicates: got:%v want:%v", preds, want)
+	}
+
+	g.RemoveStatement(statements[0])
+	it := g.AllStatements()
+	var n int
+	for it.Next() {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("unexpected number of statements after removal: got:%d want:1", n)
+	}
+}
+
+func TestInsertStatements(t *testing.T) {
+	for _, test := range insertStatementTests {
+		var wantErrs, wantAdded int
+		for _, err := range test.want {
+			if err != nil {
+				wantErrs++
+			} else {
+				wantAdded++
+			}
+		}
+
+		for _, collect := range []bool{false, true} {
+			g, _, err := graphFromReader(strings.NewReader(test.triples))
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", test.name, err)
+			}
+			statements := make([]*rdf.Statement, len(test.add))
+			for i, s := range test.add {
+				s := *s
+				statements[i] = &s
+			}
+
+			n, err := g.InsertStatements(statements, collect)
+			if !collect {
+				var want int
+				for _, err := range test.want {
+					if err != nil {
+						break
+					}
+					want++
+				}
+				if n != want {
+					t.Errorf("unexpected number of statements added for %q: got:%d want:%d", test.name, n, want)
+				}
+				if !errors.Is(err, test.want[want]) {
+					t.Errorf("unexpected error for %q: got:%v want:%v", test.name, err, test.want[want])
+				}
+				continue
+			}
+
+			if n != wantAdded {
+				t.Errorf("unexpected number of statements added for %q: got:%d want:%d", test.name, n, wantAdded)
+			}
+			errs, ok := err.(gogo.StatementErrors)
+			if !ok {
+				t.Errorf("unexpected error type for %q: %T", test.name, err)
+				continue
+			}
+			if len(errs) != wantErrs {
+				t.Errorf("unexpected number of errors for %q: got:%d want:%d", test.name, len(errs), wantErrs)
+			}
+		}
+	}
+}
+
 func graphFromReader(r io.Reader) (*gogo.Graph, []*rdf.Statement, error) {
 	g := gogo.NewGraph()
 	var statements []*rdf.Statement
@@ -0,0 +1,472 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// RankedGene is a gene product and its ranking metric value.
+type RankedGene struct {
+	ID    string
+	Score float64
+}
+
+// GSEAOptions specifies how a gene set enrichment analysis is performed.
+type GSEAOptions struct {
+	// MinSetSize and MaxSetSize are the
+	// bounds on the number of ranked gene
+	// products annotated to a term for the
+	// term's gene set to be tested. A zero
+	// value indicates no bound.
+	MinSetSize int
+	MaxSetSize int
+
+	// Aspect restricts tested terms to the
+	// given GO aspect. If Aspect is
+	// UnknownAspect, all terms are tested.
+	Aspect Aspect
+
+	// Weight is the exponent applied to
+	// the absolute ranking metric values of
+	// gene set members in the running sum.
+	// If Weight is zero, the standard GSEA
+	// weight of one is used.
+	Weight float64
+
+	// Unweighted specifies that the
+	// unweighted Kolmogorov–Smirnov statistic
+	// is used, equivalent to a Weight of zero.
+	// Weight is ignored if Unweighted is true.
+	Unweighted bool
+
+	// Permutations is the number of
+	// permutations used to construct the
+	// null distribution. If Permutations is
+	// zero, 1000 are used.
+	Permutations int
+
+	// Src is the source of randomness for
+	// permutations. If Src is nil, the
+	// global random source is used.
+	Src rand.Source
+}
+
+// GeneSetEnrichment is the result of a gene set enrichment test for a term.
+type GeneSetEnrichment struct {
+	// Term is the tested term.
+	Term rdf.Term
+
+	// Size is the number of ranked gene
+	// products annotated to the term.
+	Size int
+
+	// ES is the enrichment score, the
+	// maximum deviation from zero of the
+	// weighted running sum.
+	ES float64
+
+	// NES is the enrichment score normalised
+	// by the mean of the null enrichment
+	// scores for the term with the same sign.
+	// NES is NaN if there are no such null
+	// enrichment scores.
+	NES float64
+
+	// P is the nominal p-value, the fraction
+	// of same-signed null enrichment scores
+	// at least as extreme as ES.
+	P float64
+
+	// FDR is the false discovery rate
+	// q-value estimated from the pooled
+	// normalised null enrichment scores.
+	FDR float64
+
+	// LeadingEdge holds the gene products
+	// of the term's gene set that contribute
+	// to the enrichment score, in rank order.
+	LeadingEdge []string
+}
+
+// GSEA performs a gene set enrichment analysis of the ranked gene products
+// over gene sets defined by the annotations in s after true path
+// propagation. Gene products are ranked by decreasing Score, and the null
+// distribution is obtained by gene set permutation, randomly reassigning
+// gene products to ranks. If opts is nil, default options are used. The
+// results are sorted by increasing P, then by decreasing absolute NES and
+// then by term value.
+func (s *Annotations) GSEA(ranked []RankedGene, opts *GSEAOptions) []GeneSetEnrichment {
+	if opts == nil {
+		opts = &GSEAOptions{}
+	}
+	list := newRankedList(ranked)
+	sets := s.geneSets(list, opts)
+	if len(sets) == 0 {
+		return nil
+	}
+	perm := permFunc(opts.Src)
+	null := make([][]float64, len(sets))
+	positions := make([]int, 0, len(list.genes))
+	for i := 0; i < permutations(opts); i++ {
+		p := perm(len(list.genes))
+		for j, set := range sets {
+			positions = positions[:0]
+			for _, pos := range set.positions {
+				positions = append(positions, p[pos])
+			}
+			sort.Ints(positions)
+			es, _ := enrichmentScore(list.scores, positions, weight(opts))
+			null[j] = append(null[j], es)
+		}
+	}
+	return gseaResults(list, sets, null, opts)
+}
+
+// PhenotypeGSEA performs a gene set enrichment analysis with the same
+// semantics as GSEA, but obtains the null distribution by phenotype
+// permutation. The observed ranking is rank(labels) and each permutation
+// ranks the gene products with rank applied to a random permutation of
+// the sample phenotype labels. Gene products absent from a permuted
+// ranking are omitted from the gene sets for that permutation.
+func (s *Annotations) PhenotypeGSEA(labels []int, rank func(labels []int) []RankedGene, opts *GSEAOptions) []GeneSetEnrichment {
+	if opts == nil {
+		opts = &GSEAOptions{}
+	}
+	list := newRankedList(rank(labels))
+	sets := s.geneSets(list, opts)
+	if len(sets) == 0 {
+		return nil
+	}
+	perm := permFunc(opts.Src)
+	null := make([][]float64, len(sets))
+	shuffled := make([]int, len(labels))
+	var positions []int
+	for i := 0; i < permutations(opts); i++ {
+		for j, k := range perm(len(labels)) {
+			shuffled[j] = labels[k]
+		}
+		permuted := newRankedList(rank(shuffled))
+		for j, set := range sets {
+			positions = positions[:0]
+			for _, pos := range set.positions {
+				if p, ok := permuted.index[list.genes[pos]]; ok {
+					positions = append(positions, p)
+				}
+			}
+			sort.Ints(positions)
+			es, _ := enrichmentScore(permuted.scores, positions, weight(opts))
+			null[j] = append(null[j], es)
+		}
+	}
+	return gseaResults(list, sets, null, opts)
+}
+
+// rankedList is a list of gene products in decreasing order of score.
+type rankedList struct {
+	genes  []string
+	scores []float64
+	index  map[string]int
+}
+
+// newRankedList returns the ranked list of gene products in ranked,
+// ordered by decreasing score and then by identifier. Only the first
+// occurrence of a gene product in that order is retained.
+func newRankedList(ranked []RankedGene) rankedList {
+	r := make([]RankedGene, len(ranked))
+	copy(r, ranked)
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Score != r[j].Score {
+			return r[i].Score > r[j].Score
+		}
+		return r[i].ID < r[j].ID
+	})
+	l := rankedList{index: make(map[string]int, len(r))}
+	for _, g := range r {
+		if _, ok := l.index[g.ID]; ok {
+			continue
+		}
+		l.index[g.ID] = len(l.genes)
+		l.genes = append(l.genes, g.ID)
+		l.scores = append(l.scores, g.Score)
+	}
+	return l
+}
+
+// geneSet is the set of ranked gene products annotated to a term.
+type geneSet struct {
+	term rdf.Term
+	// positions holds the sorted ranks of
+	// the set's gene products.
+	positions []int
+}
+
+// geneSets returns the gene sets of the terms annotated to the gene
+// products in list that satisfy opts, sorted by term value.
+func (s *Annotations) geneSets(list rankedList, opts *GSEAOptions) []geneSet {
+	if len(list.genes) == 0 {
+		return nil
+	}
+	pop := s.newEnrichmentPopulation(list.genes)
+	byTerm := make(map[int64]*geneSet)
+	for pos, g := range list.genes {
+		for _, t := range pop.terms[g] {
+			set, ok := byTerm[t.UID]
+			if !ok {
+				set = &geneSet{term: t}
+				byTerm[t.UID] = set
+			}
+			set.positions = append(set.positions, pos)
+		}
+	}
+	var sets []geneSet
+	for _, set := range byTerm {
+		n := len(set.positions)
+		if opts.MinSetSize > 0 && n < opts.MinSetSize {
+			continue
+		}
+		if opts.MaxSetSize > 0 && n > opts.MaxSetSize {
+			continue
+		}
+		if opts.Aspect != UnknownAspect && pop.aspect(set.term) != opts.Aspect {
+			continue
+		}
+		sets = append(sets, *set)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].term.Value < sets[j].term.Value
+	})
+	return sets
+}
+
+// enrichmentScore returns the weighted Kolmogorov–Smirnov enrichment score
+// for the gene set with the given sorted positions in a ranked list with
+// the given scores, and the rank at which the running sum reaches it.
+// Gene set members contribute |score|^weight normalised by the sum over
+// the set, and other gene products contribute a uniform penalty.
+func enrichmentScore(scores []float64, positions []int, weight float64) (es float64, peak int) {
+	n := len(scores)
+	m := len(positions)
+	if m == 0 {
+		return 0, -1
+	}
+	var norm float64
+	for _, pos := range positions {
+		norm += math.Pow(math.Abs(scores[pos]), weight)
+	}
+	hit := func(pos int) float64 {
+		if norm == 0 {
+			return 1 / float64(m)
+		}
+		return math.Pow(math.Abs(scores[pos]), weight) / norm
+	}
+	var miss float64
+	if n > m {
+		miss = 1 / float64(n-m)
+	}
+
+	var (
+		running  float64
+		max, min float64
+		maxAt    = -1
+		minAt    = -1
+		last     = -1
+	)
+	for _, pos := range positions {
+		if misses := pos - last - 1; misses > 0 {
+			running -= float64(misses) * miss
+			if running < min {
+				min, minAt = running, pos-1
+			}
+		}
+		running += hit(pos)
+		if running > max {
+			max, maxAt = running, pos
+		}
+		last = pos
+	}
+	if max >= -min {
+		return max, maxAt
+	}
+	return min, minAt
+}
+
+// leadingEdge returns the gene products of the set that contribute to the
+// enrichment score es reached at the rank peak.
+func leadingEdge(list rankedList, set geneSet, es float64, peak int) []string {
+	var genes []string
+	for _, pos := range set.positions {
+		if (es >= 0 && pos <= peak) || (es < 0 && pos > peak) {
+			genes = append(genes, list.genes[pos])
+		}
+	}
+	return genes
+}
+
+// gseaResults returns the gene set enrichment results for the gene sets
+// given their null enrichment scores, sorted by increasing P, then by
+// decreasing absolute NES and then by term value.
+func gseaResults(list rankedList, sets []geneSet, null [][]float64, opts *GSEAOptions) []GeneSetEnrichment {
+	results := make([]GeneSetEnrichment, len(sets))
+	var posNull, negNull []float64
+	for i, set := range sets {
+		es, peak := enrichmentScore(list.scores, set.positions, weight(opts))
+		r := GeneSetEnrichment{
+			Term:        set.term,
+			Size:        len(set.positions),
+			ES:          es,
+			NES:         math.NaN(),
+			P:           1,
+			LeadingEdge: leadingEdge(list, set, es, peak),
+		}
+
+		posMean, negMean := signedMeans(null[i])
+		mean := posMean
+		if es < 0 {
+			mean = -negMean
+		}
+		if mean > 0 {
+			r.NES = es / mean
+			var n, extreme int
+			for _, v := range null[i] {
+				if (v >= 0) != (es >= 0) {
+					continue
+				}
+				n++
+				if math.Abs(v) >= math.Abs(es) {
+					extreme++
+				}
+			}
+			r.P = float64(extreme) / float64(n)
+		}
+		results[i] = r
+
+		// Pool the normalised null scores for
+		// false discovery rate estimation.
+		for _, v := range null[i] {
+			switch {
+			case v >= 0 && posMean > 0:
+				posNull = append(posNull, v/posMean)
+			case v < 0:
+				negNull = append(negNull, v/-negMean)
+			}
+		}
+	}
+	setFDR(results, posNull, negNull)
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].P != results[j].P {
+			return results[i].P < results[j].P
+		}
+		ni, nj := absNES(results[i]), absNES(results[j])
+		if ni != nj {
+			return ni > nj
+		}
+		return results[i].Term.Value < results[j].Term.Value
+	})
+	return results
+}
+
+// absNES returns the absolute NES of r, or -1 if the NES is NaN.
+func absNES(r GeneSetEnrichment) float64 {
+	if math.IsNaN(r.NES) {
+		return -1
+	}
+	return math.Abs(r.NES)
+}
+
+// signedMeans returns the means of the non-negative and of the negative
+// values in x. A mean is zero if there are no such values.
+func signedMeans(x []float64) (pos, neg float64) {
+	var nPos, nNeg int
+	for _, v := range x {
+		if v >= 0 {
+			pos += v
+			nPos++
+		} else {
+			neg += v
+			nNeg++
+		}
+	}
+	if nPos != 0 {
+		pos /= float64(nPos)
+	}
+	if nNeg != 0 {
+		neg /= float64(nNeg)
+	}
+	return pos, neg
+}
+
+// setFDR sets the FDR field of each of the results to the fraction of
+// same-signed normalised null scores at least as extreme as the result's
+// NES divided by the fraction of same-signed observed NES values at least
+// as extreme, capped at one.
+func setFDR(results []GeneSetEnrichment, posNull, negNull []float64) {
+	var posObs, negObs []float64
+	for _, r := range results {
+		switch {
+		case math.IsNaN(r.NES):
+		case r.NES >= 0:
+			posObs = append(posObs, r.NES)
+		default:
+			negObs = append(negObs, r.NES)
+		}
+	}
+	for _, x := range [][]float64{posNull, negNull, posObs, negObs} {
+		sort.Float64s(x)
+	}
+	atLeast := func(x []float64, v float64) float64 {
+		if len(x) == 0 {
+			return 0
+		}
+		return float64(len(x)-sort.SearchFloat64s(x, v)) / float64(len(x))
+	}
+	atMost := func(x []float64, v float64) float64 {
+		if len(x) == 0 {
+			return 0
+		}
+		return float64(sort.Search(len(x), func(i int) bool { return x[i] > v })) / float64(len(x))
+	}
+	for i := range results {
+		nes := results[i].NES
+		var nullFrac, obsFrac float64
+		switch {
+		case math.IsNaN(nes):
+			results[i].FDR = 1
+			continue
+		case nes >= 0:
+			nullFrac = atLeast(posNull, nes)
+			obsFrac = atLeast(posObs, nes)
+		default:
+			nullFrac = atMost(negNull, nes)
+			obsFrac = atMost(negObs, nes)
+		}
+		results[i].FDR = math.Min(1, nullFrac/obsFrac)
+	}
+}
+
+// permutations returns the number of permutations specified by opts.
+func permutations(opts *GSEAOptions) int {
+	if opts.Permutations == 0 {
+		return 1000
+	}
+	return opts.Permutations
+}
+
+// weight returns the running sum weight exponent specified by opts.
+func weight(opts *GSEAOptions) float64 {
+	switch {
+	case opts.Unweighted:
+		return 0
+	case opts.Weight == 0:
+		return 1
+	default:
+		return opts.Weight
+	}
+}
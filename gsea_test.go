@@ -0,0 +1,277 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+
+	"github.com/kortschak/gogo"
+)
+
+// gseaStore returns an annotation store with the given gene products
+// annotated to the corresponding terms.
+func gseaStore(t *testing.T, genes, terms []string) *gogo.Annotations {
+	t.Helper()
+	g := oboGraph(t, ancestryOBO, false)
+	s := gogo.NewAnnotations(gogo.Ancestry{Graph: g})
+	for i, gene := range genes {
+		err := s.Add(&gogo.Annotation{GeneProduct: gogo.GeneProduct{ID: gene}, TermID: terms[i]})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return s
+}
+
+var enrichmentScoreTests = []struct {
+	weight     float64
+	unweighted bool
+	want       []string
+}{
+	{
+		weight: 1,
+		want: []string{
+			"0007165 2 0.6667 [a]",
+			"0009987 2 0.6667 [a]",
+			"0010646 2 -0.5 [b d]",
+			"0050789 2 -0.5 [b d]",
+			"0065007 2 -0.5 [b d]",
+		},
+	},
+	{
+		weight: 0,
+		want: []string{
+			"0007165 2 0.6667 [a]",
+			"0009987 2 0.6667 [a]",
+			"0010646 2 -0.5 [b d]",
+			"0050789 2 -0.5 [b d]",
+			"0065007 2 -0.5 [b d]",
+		},
+	},
+	{
+		weight:     1,
+		unweighted: true,
+		want: []string{
+			"0007165 2 0.5 [a]",
+			"0009987 2 0.5 [a]",
+			"0010646 2 -0.5 [b d]",
+			"0050789 2 -0.5 [b d]",
+			"0065007 2 -0.5 [b d]",
+		},
+	},
+}
+
+func TestEnrichmentScore(t *testing.T) {
+	s := gseaStore(t,
+		[]string{"a", "b", "c", "d"},
+		[]string{"GO:0007165", "GO:0010646", "GO:0007165", "GO:0010646"},
+	)
+	ranked := []gogo.RankedGene{{"c", 2}, {"a", 4}, {"d", 1}, {"b", 3}}
+	for _, test := range enrichmentScoreTests {
+		results := s.GSEA(ranked, &gogo.GSEAOptions{
+			MaxSetSize:   2,
+			Weight:       test.weight,
+			Unweighted:   test.unweighted,
+			Permutations: 10,
+			Src:          rand.NewSource(1),
+		})
+		got := make(map[string]string)
+		var ids []string
+		for _, r := range results {
+			id := r.Term.Value[strings.Index(r.Term.Value, "GO_")+3 : len(r.Term.Value)-1]
+			ids = append(ids, id)
+			got[id] = fmt.Sprintf("%s %d %.4g %v", id, r.Size, r.ES, r.LeadingEdge)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("unexpected number of results for weight=%v unweighted=%t: got:%v want:%d",
+				test.weight, test.unweighted, ids, len(test.want))
+		}
+		for _, want := range test.want {
+			id := want[:strings.Index(want, " ")]
+			if got[id] != want {
+				t.Errorf("unexpected result for weight=%v unweighted=%t: got:%q want:%q",
+					test.weight, test.unweighted, got[id], want)
+			}
+		}
+	}
+}
+
+func TestGSEADefaultWeight(t *testing.T) {
+	s := gseaStore(t,
+		[]string{"a", "b", "c", "d"},
+		[]string{"GO:0007165", "GO:0010646", "GO:0007165", "GO:0010646"},
+	)
+	ranked := []gogo.RankedGene{{"c", 2}, {"a", 4}, {"d", 1}, {"b", 3}}
+
+	// The ranked list is a, b, c, d and gene products not
+	// in a set of two contribute a penalty of 1/2. With the
+	// standard weight of one, the running sum for {a, c}
+	// steps by 4/6, -1/2, 2/6 and -1/2, reaching a maximum
+	// of 2/3, and for {b, d} steps by -1/2, 3/4, -1/2 and
+	// 1/4, reaching a minimum of -1/2.
+	want := map[string]float64{
+		"0007165": 2.0 / 3,
+		"0010646": -0.5,
+	}
+	results := s.GSEA(ranked, nil)
+	for _, r := range results {
+		id := r.Term.Value[strings.Index(r.Term.Value, "GO_")+3 : len(r.Term.Value)-1]
+		es, ok := want[id]
+		if !ok {
+			continue
+		}
+		delete(want, id)
+		if !scalar.EqualWithinAbsOrRel(r.ES, es, 1e-12, 1e-12) {
+			t.Errorf("unexpected enrichment score for %s: got:%v want:%v", id, r.ES, es)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("missing results: %v", want)
+	}
+}
+
+func TestGSEA(t *testing.T) {
+	var (
+		genes, terms []string
+		ranked       []gogo.RankedGene
+	)
+	for i := 0; i < 20; i++ {
+		gene := fmt.Sprintf("g%d", i)
+		term := "GO:0008150"
+		switch {
+		case i < 5:
+			term = "GO:0007165"
+		case i >= 15:
+			term = "GO:0010646"
+		}
+		genes = append(genes, gene)
+		terms = append(terms, term)
+		ranked = append(ranked, gogo.RankedGene{ID: gene, Score: float64(10 - i)})
+	}
+	s := gseaStore(t, genes, terms)
+
+	opts := &gogo.GSEAOptions{MaxSetSize: 10, Weight: 1, Permutations: 200, Src: rand.NewSource(1)}
+	results := s.GSEA(ranked, opts)
+	checkGSEA(t, "gene set", results, true)
+
+	opts.Src = rand.NewSource(1)
+	again := s.GSEA(ranked, opts)
+	for i := range results {
+		if results[i].NES != again[i].NES || results[i].P != again[i].P || results[i].FDR != again[i].FDR {
+			t.Errorf("gene set permutation not reproducible for %s: got:%+v want:%+v",
+				again[i].Term.Value, again[i], results[i])
+		}
+	}
+}
+
+func TestPhenotypeGSEA(t *testing.T) {
+	const genes, samples = 20, 10
+	var terms, ids []string
+	for i := 0; i < genes; i++ {
+		term := "GO:0008150"
+		switch {
+		case i < 5:
+			term = "GO:0007165"
+		case i >= 15:
+			term = "GO:0010646"
+		}
+		ids = append(ids, fmt.Sprintf("g%d", i))
+		terms = append(terms, term)
+	}
+	s := gseaStore(t, ids, terms)
+
+	labels := []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}
+	expr := make([][]float64, genes)
+	for i := range expr {
+		expr[i] = make([]float64, samples)
+		for j := range expr[i] {
+			v := float64((i*7+j*3)%5) / 10
+			switch {
+			case i < 5 && labels[j] == 1:
+				v += 2
+			case i >= 15 && labels[j] == 1:
+				v -= 2
+			}
+			expr[i][j] = v
+		}
+	}
+	// rank ranks genes by the difference in mean
+	// expression between the two phenotypes.
+	rank := func(labels []int) []gogo.RankedGene {
+		ranked := make([]gogo.RankedGene, genes)
+		for i, row := range expr {
+			var sum [2]float64
+			var n [2]int
+			for j, v := range row {
+				sum[labels[j]] += v
+				n[labels[j]]++
+			}
+			ranked[i] = gogo.RankedGene{ID: ids[i], Score: sum[1]/float64(n[1]) - sum[0]/float64(n[0])}
+		}
+		return ranked
+	}
+
+	opts := &gogo.GSEAOptions{MaxSetSize: 10, Weight: 1, Permutations: 200, Src: rand.NewSource(1)}
+	results := s.PhenotypeGSEA(labels, rank, opts)
+	checkGSEA(t, "phenotype", results, false)
+
+	opts.Src = rand.NewSource(1)
+	again := s.PhenotypeGSEA(labels, rank, opts)
+	for i := range results {
+		if results[i].NES != again[i].NES || results[i].P != again[i].P || results[i].FDR != again[i].FDR {
+			t.Errorf("phenotype permutation not reproducible for %s: got:%+v want:%+v",
+				again[i].Term.Value, again[i], results[i])
+		}
+	}
+}
+
+// checkGSEA checks that the signal transduction gene set is positively
+// enriched and the regulation of cell communication gene set is negatively
+// enriched. If significant is true, the enrichments must have a p-value
+// below 0.05.
+func checkGSEA(t *testing.T, kind string, results []gogo.GeneSetEnrichment, significant bool) {
+	t.Helper()
+	want := map[string]struct {
+		es      float64
+		leading string
+	}{
+		goTerm("0007165", false): {es: 1, leading: "[g0 g1 g2 g3 g4]"},
+		goTerm("0010646", false): {es: -1, leading: "[g15 g16 g17 g18 g19]"},
+	}
+	var found int
+	for _, r := range results {
+		w, ok := want[r.Term.Value]
+		if !ok {
+			continue
+		}
+		found++
+		if r.ES != w.es {
+			t.Errorf("unexpected %s ES for %s: got:%v want:%v", kind, r.Term.Value, r.ES, w.es)
+		}
+		if (r.NES > 0) != (w.es > 0) {
+			t.Errorf("unexpected %s NES sign for %s: got:%v", kind, r.Term.Value, r.NES)
+		}
+		if r.P < 0 || r.P > 1 || (significant && r.P >= 0.05) {
+			t.Errorf("unexpected %s p-value for %s: got:%v", kind, r.Term.Value, r.P)
+		}
+		if r.FDR < 0 || r.FDR > 1 {
+			t.Errorf("unexpected %s false discovery rate for %s: got:%v", kind, r.Term.Value, r.FDR)
+		}
+		leading := append([]string(nil), r.LeadingEdge...)
+		sort.Strings(leading)
+		if got := fmt.Sprint(leading); got != w.leading {
+			t.Errorf("unexpected %s leading edge for %s: got:%v want:%v", kind, r.Term.Value, got, w.leading)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("missing %s results: got %d of %d", kind, found, len(want))
+	}
+}
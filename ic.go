@@ -0,0 +1,208 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// InformationContent is an information content model for ontology terms.
+// Information content values are keyed by term value, so a model may only
+// be used with graphs in the same namespace form as the graph it was
+// computed from.
+type InformationContent struct {
+	values map[string]float64
+	max    float64
+}
+
+// NewAnnotationIC returns the information content model for the annotation
+// corpus s. The information content of a term t is -log p(t) where p(t) is
+// the number of gene products annotated to t after true path propagation,
+// relative to the number annotated to the most frequently annotated of t's
+// ancestors, the root of t's sub-ontology in a fully propagated corpus.
+// Terms without annotations are not included in the model.
+func NewAnnotationIC(s *Annotations) *InformationContent {
+	freq := make(map[int64]int)
+	terms := make(map[int64]rdf.Term)
+	for _, gene := range s.Genes() {
+		for _, t := range s.PropagatedTermsFor(gene) {
+			freq[t.UID]++
+			terms[t.UID] = t
+		}
+	}
+
+	ic := &InformationContent{values: make(map[string]float64, len(freq))}
+	for uid, n := range freq {
+		t := terms[uid]
+		total := n
+		for _, a := range s.ancestry.AncestorsOf(t) {
+			if freq[a.Term.UID] > total {
+				total = freq[a.Term.UID]
+			}
+		}
+		ic.set(t.Value, -math.Log(float64(n)/float64(total)))
+	}
+	return ic
+}
+
+// Intrinsic specifies an intrinsic information content measure.
+type Intrinsic int
+
+const (
+	// Seco is the intrinsic information content
+	// of Seco et al. (2004), based on the number
+	// of descendants of a term:
+	//  IC(t) = 1 - log(desc(t)+1)/log(N)
+	// where N is the number of terms in the
+	// ontology.
+	Seco Intrinsic = iota
+
+	// Zhou is the intrinsic information content
+	// of Zhou et al. (2008), which combines the
+	// Seco measure with the depth of a term:
+	//  IC(t) = (Seco(t) + log(depth(t))/log(depth_max))/2
+	// where depth(t) is one for roots.
+	Zhou
+)
+
+// NewIntrinsicIC returns an information content model computed from the
+// structure of the ontology queried by a using the given measure. Terms
+// are the nodes of a.Graph that are terms of the queried ontology.
+func NewIntrinsicIC(a Ancestry, measure Intrinsic) (*InformationContent, error) {
+	if measure != Seco && measure != Zhou {
+		return nil, fmt.Errorf("gogo: invalid intrinsic measure: %d", measure)
+	}
+
+	var terms []rdf.Term
+	nodes := a.Graph.Nodes()
+	for nodes.Next() {
+		t := nodes.Node().(rdf.Term)
+		if a.isTerm(t) && !(a.ExcludeDeprecated && a.Graph.isObsolete(t, a.ontology())) {
+			terms = append(terms, t)
+		}
+	}
+	ic := &InformationContent{values: make(map[string]float64, len(terms))}
+	if len(terms) < 2 {
+		for _, t := range terms {
+			ic.set(t.Value, 0)
+		}
+		return ic, nil
+	}
+
+	logN := math.Log(float64(len(terms)))
+	depth := make(map[int64]int, len(terms))
+	maxDepth := 1
+	if measure == Zhou {
+		for _, t := range terms {
+			d := a.Depth(t) + 1
+			depth[t.UID] = d
+			if d > maxDepth {
+				maxDepth = d
+			}
+		}
+	}
+	for _, t := range terms {
+		v := 1 - math.Log(float64(len(a.DescendantsOf(t))+1))/logN
+		if measure == Zhou {
+			var d float64
+			if maxDepth > 1 {
+				d = math.Log(float64(depth[t.UID])) / math.Log(float64(maxDepth))
+			}
+			v = (v + d) / 2
+		}
+		ic.set(t.Value, v)
+	}
+	return ic, nil
+}
+
+// set sets the information content of the term with the given value.
+func (ic *InformationContent) set(term string, v float64) {
+	ic.values[term] = v
+	if v > ic.max {
+		ic.max = v
+	}
+}
+
+// Of returns the information content of the term t and whether t is in
+// the model.
+func (ic *InformationContent) Of(t rdf.Term) (v float64, ok bool) {
+	v, ok = ic.values[t.Value]
+	return v, ok
+}
+
+// Score returns the information content of the term t, or zero if t is
+// not in the model. Score may be used as the score function for ranking
+// terms by Ancestry.RankedLowestCommonAncestors.
+func (ic *InformationContent) Score(t rdf.Term) float64 {
+	return ic.values[t.Value]
+}
+
+// Max returns the maximum information content of any term in the model.
+func (ic *InformationContent) Max() float64 {
+	return ic.max
+}
+
+// Len returns the number of terms in the model.
+func (ic *InformationContent) Len() int {
+	return len(ic.values)
+}
+
+// WriteTo writes the model to w as tab-separated term value and information
+// content pairs, one per line in lexical order of term value.
+func (ic *InformationContent) WriteTo(w io.Writer) (int64, error) {
+	terms := make([]string, 0, len(ic.values))
+	for t := range ic.values {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, t := range terms {
+		c, err := fmt.Fprintf(bw, "%s\t%s\n", t, strconv.FormatFloat(ic.values[t], 'g', -1, 64))
+		n += int64(c)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// ReadInformationContent returns an information content model read from r
+// in the format written by InformationContent.WriteTo. Input compressed
+// with gzip is detected and decompressed.
+func ReadInformationContent(r io.Reader) (*InformationContent, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	ic := &InformationContent{values: make(map[string]float64)}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if text == "" {
+			continue
+		}
+		i := strings.LastIndex(text, "\t")
+		if i < 0 {
+			return nil, &ParseError{Format: "ic", Line: line, Err: errors.New("missing value")}
+		}
+		v, err := strconv.ParseFloat(text[i+1:], 64)
+		if err != nil {
+			return nil, &ParseError{Format: "ic", Line: line, Err: err}
+		}
+		ic.set(text[:i], v)
+	}
+	return ic, sc.Err()
+}
@@ -0,0 +1,135 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+
+	"github.com/kortschak/gogo"
+)
+
+func TestAnnotationIC(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		s := annotationStore(t, gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf})
+		ic := gogo.NewAnnotationIC(s)
+
+		want := map[string]float64{
+			"0008150": 0,
+			"0009987": math.Log(3.0 / 2),
+			"0007154": math.Log(3),
+			"0007165": math.Log(3),
+			"0010646": math.Log(3),
+			"0050789": math.Log(3),
+			"0065007": math.Log(3),
+		}
+		if ic.Len() != len(want) {
+			t.Errorf("unexpected number of terms local=%t: got:%d want:%d", local, ic.Len(), len(want))
+		}
+		for id, v := range want {
+			term, ok := g.TermFor(goTerm(id, local))
+			if !ok {
+				t.Fatalf("missing term for %q", id)
+			}
+			got, ok := ic.Of(term)
+			if !ok || !scalar.EqualWithinAbsOrRel(got, v, 1e-12, 1e-12) {
+				t.Errorf("unexpected IC for %s local=%t: got:%v %t want:%v", id, local, got, ok, v)
+			}
+		}
+		term, _ := g.TermFor(goTerm("0010647", local))
+		if _, ok := ic.Of(term); ok {
+			t.Errorf("unexpected IC for unannotated term local=%t", local)
+		}
+		if !scalar.EqualWithinAbsOrRel(ic.Max(), math.Log(3), 1e-12, 1e-12) {
+			t.Errorf("unexpected maximum IC local=%t: got:%v want:%v", local, ic.Max(), math.Log(3))
+		}
+	}
+}
+
+func TestIntrinsicIC(t *testing.T) {
+	g := oboGraph(t, ancestryOBO, false)
+	a := gogo.Ancestry{Graph: g}
+	seco, err := gogo.NewIntrinsicIC(a, gogo.Seco)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zhou, err := gogo.NewIntrinsicIC(a, gogo.Zhou)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seco.Len() != 10 || zhou.Len() != 10 {
+		t.Fatalf("unexpected number of terms: got:%d and %d want:10", seco.Len(), zhou.Len())
+	}
+
+	logN := math.Log(10)
+	for _, test := range []struct {
+		id         string
+		seco, zhou float64
+	}{
+		{id: "0008150", seco: 1 - math.Log(8)/logN, zhou: (1 - math.Log(8)/logN) / 2},
+		{id: "0050789", seco: 1 - math.Log(3)/logN, zhou: (1 - math.Log(3)/logN + math.Log(3)/math.Log(5)) / 2},
+		{id: "0010647", seco: 1, zhou: 1},
+		{id: "0005634", seco: 1, zhou: (1 + math.Log(2)/math.Log(5)) / 2},
+	} {
+		term, _ := g.TermFor(goTerm(test.id, false))
+		if got := seco.Score(term); !scalar.EqualWithinAbsOrRel(got, test.seco, 1e-12, 1e-12) {
+			t.Errorf("unexpected Seco IC for %s: got:%v want:%v", test.id, got, test.seco)
+		}
+		if got := zhou.Score(term); !scalar.EqualWithinAbsOrRel(got, test.zhou, 1e-12, 1e-12) {
+			t.Errorf("unexpected Zhou IC for %s: got:%v want:%v", test.id, got, test.zhou)
+		}
+	}
+
+	x, _ := g.TermFor(goTerm("0010647", false))
+	y, _ := g.TermFor(goTerm("0007165", false))
+	lcas := a.RankedLowestCommonAncestors(x, y, seco.Score)
+	if len(lcas) != 1 || lcas[0].Value != goTerm("0008150", false) {
+		t.Errorf("unexpected ranked lowest common ancestors: %v", lcas)
+	}
+
+	_, err = gogo.NewIntrinsicIC(a, -1)
+	if err == nil {
+		t.Error("expected error for invalid intrinsic measure")
+	}
+}
+
+func TestInformationContentRoundTrip(t *testing.T) {
+	g := oboGraph(t, ancestryOBO, false)
+	ic, err := gogo.NewIntrinsicIC(gogo.Ancestry{Graph: g}, gogo.Zhou)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	_, err = ic.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := buf.String()
+
+	got, err := gogo.ReadInformationContent(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Len() != ic.Len() || got.Max() != ic.Max() {
+		t.Errorf("unexpected model: got:%d %v want:%d %v", got.Len(), got.Max(), ic.Len(), ic.Max())
+	}
+	buf.Reset()
+	_, err = got.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("round trip does not match:\ngot:\n%s\nwant:\n%s", &buf, want)
+	}
+
+	_, err = gogo.ReadInformationContent(bytes.NewBufferString("<a>\tx\n"))
+	if err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
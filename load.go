@@ -0,0 +1,174 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// LoadOptions specifies how statements are loaded into a Graph.
+type LoadOptions struct {
+	// Canonicalize specifies that blank nodes should be
+	// relabelled using the URDNA2015 algorithm before the
+	// statements are added to the graph. This reduces the
+	// memory footprint of graphs with many blank nodes, but
+	// requires that all the statements are held in memory
+	// before any are added.
+	Canonicalize bool
+
+	// Filter, if not nil, is called on each decoded statement
+	// before it is added to the graph. The terms of the statement
+	// have zero UIDs when Filter is called and Filter may alter
+	// their values. Only statements for which Filter returns true
+	// are added to the graph.
+	Filter func(*rdf.Statement) bool
+}
+
+// LoadStats holds the outcome of a Load or LoadFile call.
+type LoadStats struct {
+	// Accepted is the number of statements
+	// added to the graph.
+	Accepted int
+
+	// Filtered is the number of statements
+	// excluded by the LoadOptions Filter.
+	Filtered int
+
+	// Rejected is the number of statements
+	// that could not be parsed or added to
+	// the graph.
+	Rejected int
+
+	// Errors holds the reason for each
+	// rejected statement.
+	Errors []error
+}
+
+// Load reads N-Triples or N-Quads from r and adds the statements to the graph
+// according to opts. If opts is nil, all statements are added without blank
+// node canonicalisation. Input compressed with gzip is detected and
+// decompressed. Statements that cannot be parsed or that cannot be added to
+// the graph are skipped and recorded in the returned LoadStats. The returned
+// error is only non-nil if reading from r fails.
+func (g *Graph) Load(r io.Reader, opts *LoadOptions) (LoadStats, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	return g.load(rdf.NewDecoder(r).Unmarshal, opts)
+}
+
+// load adds the statements returned by next to the graph according to opts
+// until next returns io.EOF or an error that is not a syntax error.
+func (g *Graph) load(next func() (*rdf.Statement, error), opts *LoadOptions) (LoadStats, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	var stats LoadStats
+
+	var statements []*rdf.Statement
+	for {
+		s, err := next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			if !isSyntaxError(err) {
+				return stats, err
+			}
+			stats.Rejected++
+			stats.Errors = append(stats.Errors, err)
+			continue
+		}
+
+		// Terms from the decoder have UIDs that are only
+		// consistent within the stream, so clear them to
+		// allow the graph to assign its own.
+		zeroUIDs(s)
+		if opts.Filter != nil && !opts.Filter(s) {
+			stats.Filtered++
+			continue
+		}
+
+		if opts.Canonicalize {
+			statements = append(statements, s)
+			continue
+		}
+		stats.add(g, s)
+	}
+
+	if opts.Canonicalize {
+		var err error
+		statements, err = rdf.URDNA2015(statements, statements)
+		if err != nil {
+			return stats, err
+		}
+		for _, s := range statements {
+			zeroUIDs(s)
+			stats.add(g, s)
+		}
+	}
+
+	return stats, nil
+}
+
+// LoadFile reads N-Triples or N-Quads from the file at path and adds the
+// statements to the graph with the same semantics as Load.
+func (g *Graph) LoadFile(path string, opts *LoadOptions) (LoadStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	defer f.Close()
+	return g.Load(f, opts)
+}
+
+// add adds s to g, recording the outcome in the receiver.
+func (stats *LoadStats) add(g *Graph, s *rdf.Statement) {
+	err := g.InsertStatement(s)
+	if err != nil {
+		stats.Rejected++
+		stats.Errors = append(stats.Errors, err)
+		return
+	}
+	stats.Accepted++
+}
+
+// decompress returns a reader that reads decompressed data from r if
+// r holds gzip-compressed data, and the data from r otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// isSyntaxError returns whether err is an RDF parse error that
+// only affects a single statement.
+func isSyntaxError(err error) bool {
+	return errors.Is(err, rdf.ErrInvalid) ||
+		errors.Is(err, rdf.ErrIncomplete) ||
+		errors.Is(err, rdf.ErrInvalidTerm) ||
+		errors.Is(err, rdf.ErrIncompleteTerm)
+}
+
+// zeroUIDs sets the UIDs of all the terms in s to zero.
+func zeroUIDs(s *rdf.Statement) {
+	s.Subject.UID = 0
+	s.Predicate.UID = 0
+	s.Object.UID = 0
+	s.Label.UID = 0
+}
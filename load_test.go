@@ -0,0 +1,161 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var loadTests = []struct {
+	name    string
+	triples string
+	opts    *gogo.LoadOptions
+
+	wantAccepted int
+	wantFiltered int
+	wantRejected int
+	want         string
+}{
+	{
+		name: "all",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:c> <ex:q> "c" .
+`,
+		wantAccepted: 3,
+		want: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:c> <ex:q> "c" .
+`,
+	},
+	{
+		name: "filter",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+<ex:c> <ex:q> "c" .
+`,
+		opts: &gogo.LoadOptions{
+			Filter: func(s *rdf.Statement) bool { return s.Predicate.Value == "<ex:p>" },
+		},
+		wantAccepted: 2,
+		wantFiltered: 1,
+		want: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+`,
+	},
+	{
+		name: "transform",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> <ex:c> .
+`,
+		opts: &gogo.LoadOptions{
+			Filter: func(s *rdf.Statement) bool {
+				s.Predicate.Value = "<ex:q>"
+				return true
+			},
+		},
+		wantAccepted: 2,
+		want: `
+<ex:a> <ex:q> <ex:b> .
+<ex:b> <ex:q> <ex:c> .
+`,
+	},
+	{
+		name: "rejected",
+		triples: `
+<ex:a> <ex:p> <ex:b> .
+<ex:b> <ex:p> .
+<ex:b> <http://example.com/p> <ex:c> .
+<ex:c> <ex:p> <ex:a> .
+`,
+		wantAccepted: 2,
+		wantRejected: 2,
+		want: `
+<ex:a> <ex:p> <ex:b> .
+<ex:c> <ex:p> <ex:a> .
+`,
+	},
+	{
+		name: "canonicalize",
+		triples: `
+<ex:a> <ex:p> _:x .
+_:x <ex:p> <ex:c> .
+`,
+		opts:         &gogo.LoadOptions{Canonicalize: true},
+		wantAccepted: 2,
+		want: `
+<ex:a> <ex:p> _:c14n0 .
+_:c14n0 <ex:p> <ex:c> .
+`,
+	},
+}
+
+func TestLoad(t *testing.T) {
+	for _, test := range loadTests {
+		for _, compress := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(test.triples)
+			if compress {
+				var buf bytes.Buffer
+				w := gzip.NewWriter(&buf)
+				_, err := io.Copy(w, r)
+				if err != nil {
+					t.Fatalf("unexpected error compressing input: %v", err)
+				}
+				w.Close()
+				r = &buf
+			}
+
+			g := gogo.NewGraph()
+			stats, err := g.Load(r, test.opts)
+			if err != nil {
+				t.Errorf("unexpected error for %q compress=%t: %v", test.name, compress, err)
+				continue
+			}
+			if stats.Accepted != test.wantAccepted || stats.Filtered != test.wantFiltered || stats.Rejected != test.wantRejected {
+				t.Errorf("unexpected counts for %q compress=%t: got:%d/%d/%d want:%d/%d/%d",
+					test.name, compress,
+					stats.Accepted, stats.Filtered, stats.Rejected,
+					test.wantAccepted, test.wantFiltered, test.wantRejected)
+			}
+			if len(stats.Errors) != stats.Rejected {
+				t.Errorf("mismatched error count for %q compress=%t: got:%d want:%d",
+					test.name, compress, len(stats.Errors), stats.Rejected)
+			}
+			for _, err := range stats.Errors {
+				var serr *gogo.StatementError
+				if errors.As(err, &serr) && !errors.Is(err, gogo.ErrNamespace) {
+					t.Errorf("unexpected rejection for %q compress=%t: %v", test.name, compress, err)
+				}
+			}
+
+			var gotStatements []string
+			it := g.AllStatements()
+			for it.Next() {
+				gotStatements = append(gotStatements, it.Statement().String())
+			}
+			sort.Strings(gotStatements)
+			got := strings.TrimSpace(strings.Join(gotStatements, "\n"))
+			want := strings.TrimSpace(test.want)
+			if got != want {
+				t.Errorf("unexpected result for %q compress=%t:\ngot:\n%s\nwant:\n%s", test.name, compress, got, want)
+			}
+		}
+	}
+}
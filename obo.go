@@ -0,0 +1,605 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// LoadOBO reads an OBO 1.4 flat-file ontology from r and adds its [Term] and
+// [Typedef] stanzas to the graph according to opts. Input compressed with
+// gzip is detected and decompressed.
+//
+// Stanzas are translated to statements using the same owl, rdfs, obo and
+// oboInOwl vocabulary used by the OWL rendering of OBO ontologies, so the
+// graph can be queried in the same way as a graph loaded from the N-Triples
+// serialisation of the OWL file. The statements are in the namespace form
+// already used by the graph, or the global form if the graph is empty.
+// Relationships and intersection_of tags are translated to owl:Restriction
+// blank nodes; the blank node labels are derived from the content of the
+// restriction so loading the same ontology twice does not duplicate them.
+// Definition and synonym xrefs are held by owl:Axiom blank nodes annotating
+// the definition or synonym statement with oboInOwl:hasDbXref.
+//
+// Statements that cannot be added to the graph are skipped and recorded
+// in the returned LoadStats. The returned error is non-nil if reading from
+// r fails or the input is not a valid OBO document.
+func (g *Graph) LoadOBO(r io.Reader, opts *LoadOptions) (LoadStats, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	doc, err := parseOBO(r)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	namespace := g.namespace
+	if namespace != local {
+		namespace = global
+	}
+	statements, err := doc.statements(namespace)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	return g.load(sliceStatements(statements), opts)
+}
+
+// sliceStatements returns a function that returns the statements in s
+// in order and then io.EOF.
+func sliceStatements(s []*rdf.Statement) func() (*rdf.Statement, error) {
+	return func() (*rdf.Statement, error) {
+		if len(s) == 0 {
+			return nil, io.EOF
+		}
+		next := s[0]
+		s = s[1:]
+		return next, nil
+	}
+}
+
+// oboDocument is a parsed OBO document.
+type oboDocument struct {
+	// ontology is the value of the ontology
+	// header tag, e.g. "go".
+	ontology string
+
+	// defaultNamespace is the value of the
+	// default-namespace header tag.
+	defaultNamespace string
+
+	stanzas []*stanza
+}
+
+// stanza is an OBO [Term] or [Typedef] stanza.
+type stanza struct {
+	kind string
+
+	id        string
+	name      string
+	namespace string
+	def       string
+	defXrefs  []string
+	comments  []string
+	subsets   []string
+	synonyms  []synonym
+	xrefs     []string
+	altIDs    []string
+
+	isA            []string
+	relationships  []relationship
+	intersectionOf []relationship
+	equivalentTo   []string
+
+	obsolete   bool
+	replacedBy []string
+	consider   []string
+}
+
+// synonym is an OBO synonym.
+type synonym struct {
+	text  string
+	scope string
+	xrefs []string
+}
+
+// relationship is an OBO relationship. For intersection_of
+// relationships, an empty rel indicates the genus.
+type relationship struct {
+	rel    string
+	target string
+}
+
+// parseOBO parses an OBO 1.4 document from r.
+func parseOBO(r io.Reader) (*oboDocument, error) {
+	var (
+		doc  oboDocument
+		curr *stanza
+		line int
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == '!' {
+			continue
+		}
+		if text[0] == '[' {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("gogo: obo: line %d: malformed stanza header: %q", line, text)
+			}
+			curr = &stanza{kind: text[1 : len(text)-1]}
+			doc.stanzas = append(doc.stanzas, curr)
+			continue
+		}
+		i := strings.Index(text, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("gogo: obo: line %d: missing tag: %q", line, text)
+		}
+		tag := strings.TrimSpace(text[:i])
+		value := strings.TrimSpace(text[i+1:])
+
+		if curr == nil {
+			switch tag {
+			case "ontology":
+				doc.ontology = value
+			case "default-namespace":
+				doc.defaultNamespace = value
+			}
+			continue
+		}
+		err := curr.set(tag, value)
+		if err != nil {
+			return nil, fmt.Errorf("gogo: obo: line %d: %w", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// set sets the stanza field corresponding to the tag-value pair.
+// Unknown tags are ignored.
+func (s *stanza) set(tag, value string) error {
+	switch tag {
+	case "def":
+		text, rest, err := unquote(value)
+		if err != nil {
+			return fmt.Errorf("def: %w", err)
+		}
+		s.def = text
+		s.defXrefs = xrefList(rest)
+		return nil
+	case "synonym":
+		text, rest, err := unquote(value)
+		if err != nil {
+			return fmt.Errorf("synonym: %w", err)
+		}
+		syn := synonym{text: text, scope: "RELATED"}
+		rest = stripComment(rest)
+		if f := strings.Fields(rest); len(f) != 0 && !strings.HasPrefix(f[0], "[") {
+			syn.scope = f[0]
+		}
+		syn.xrefs = xrefList(rest)
+		s.synonyms = append(s.synonyms, syn)
+		return nil
+	case "comment":
+		s.comments = append(s.comments, unescape(value))
+		return nil
+	}
+
+	value = stripComment(value)
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: missing value", tag)
+	}
+	switch tag {
+	case "id":
+		s.id = fields[0]
+	case "name":
+		s.name = unescape(value)
+	case "namespace":
+		s.namespace = fields[0]
+	case "subset":
+		s.subsets = append(s.subsets, fields[0])
+	case "xref":
+		s.xrefs = append(s.xrefs, fields[0])
+	case "alt_id":
+		s.altIDs = append(s.altIDs, fields[0])
+	case "is_a":
+		s.isA = append(s.isA, fields[0])
+	case "equivalent_to":
+		s.equivalentTo = append(s.equivalentTo, fields[0])
+	case "relationship":
+		if len(fields) < 2 {
+			return fmt.Errorf("relationship: missing target: %q", value)
+		}
+		s.relationships = append(s.relationships, relationship{rel: fields[0], target: fields[1]})
+	case "intersection_of":
+		if len(fields) == 1 {
+			s.intersectionOf = append(s.intersectionOf, relationship{target: fields[0]})
+		} else {
+			s.intersectionOf = append(s.intersectionOf, relationship{rel: fields[0], target: fields[1]})
+		}
+	case "is_obsolete":
+		s.obsolete = fields[0] == "true"
+	case "replaced_by":
+		s.replacedBy = append(s.replacedBy, fields[0])
+	case "consider":
+		s.consider = append(s.consider, fields[0])
+	}
+	return nil
+}
+
+// unquote returns the unescaped text of the quoted string at the start of
+// value and the remainder of value after the closing quote.
+func unquote(value string) (text, rest string, err error) {
+	if !strings.HasPrefix(value, `"`) {
+		return "", value, fmt.Errorf("missing quoted text: %q", value)
+	}
+	escaped := false
+	for i := 1; i < len(value); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case value[i] == '\\':
+			escaped = true
+		case value[i] == '"':
+			return unescape(value[1:i]), strings.TrimSpace(value[i+1:]), nil
+		}
+	}
+	return "", value, fmt.Errorf("unterminated quoted text: %q", value)
+}
+
+// unescape returns s with OBO escape sequences interpreted.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var buf strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+				continue
+			}
+			buf.WriteRune(r)
+			continue
+		}
+		escaped = false
+		switch r {
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'W':
+			buf.WriteByte(' ')
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// stripComment returns value with any trailing modifiers and
+// comment removed.
+func stripComment(value string) string {
+	quoted := false
+	escaped := false
+	for i, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == '!' || r == '{'):
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// xrefList returns the xrefs held in the bracketed list in value.
+func xrefList(value string) []string {
+	i := strings.Index(value, "[")
+	j := strings.LastIndex(value, "]")
+	if i < 0 || j < i {
+		return nil
+	}
+	var xrefs []string
+	for _, x := range strings.Split(value[i+1:j], ",") {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+		// Drop any quoted xref description.
+		if k := strings.Index(x, ` "`); k >= 0 {
+			x = x[:k]
+		}
+		xrefs = append(xrefs, x)
+	}
+	return xrefs
+}
+
+// statements returns the RDF statements corresponding to the document in
+// the given namespace form.
+func (doc *oboDocument) statements(namespace int) ([]*rdf.Statement, error) {
+	t := oboTranslator{
+		namespace: namespace,
+		doc:       doc,
+		relations: make(map[string]string),
+	}
+	if t.doc.ontology == "" {
+		t.doc.ontology = "obo"
+	}
+	for _, r := range knownRelations {
+		t.relations[r.Name] = r.ID
+	}
+	for _, s := range doc.stanzas {
+		if s.kind != "Typedef" {
+			continue
+		}
+		for _, x := range s.xrefs {
+			// Relations are mapped to their
+			// BFO or RO identifiers.
+			if strings.HasPrefix(x, "BFO:") || strings.HasPrefix(x, "RO:") {
+				t.relations[s.id] = x
+				break
+			}
+		}
+	}
+
+	t.add(iriTerm(namespace, "obo:"+t.doc.ontology+".owl"), "rdf:type", iriTerm(namespace, "owl:Ontology"))
+	for _, s := range doc.stanzas {
+		switch s.kind {
+		case "Term", "Typedef":
+		default:
+			continue
+		}
+		err := t.translate(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.statements, nil
+}
+
+// oboTranslator translates OBO stanzas into RDF statements.
+type oboTranslator struct {
+	namespace int
+	doc       *oboDocument
+
+	// relations maps relation names to
+	// their OBO identifiers.
+	relations map[string]string
+
+	statements []*rdf.Statement
+}
+
+// translate adds the statements describing s to the translator.
+func (t *oboTranslator) translate(s *stanza) error {
+	if s.id == "" {
+		return fmt.Errorf("gogo: obo: %s stanza missing id", s.kind)
+	}
+	subj, err := t.entity(s.id)
+	if err != nil {
+		return err
+	}
+
+	typ := "owl:Class"
+	subClassOf := "rdfs:subClassOf"
+	if s.kind == "Typedef" {
+		typ = "owl:ObjectProperty"
+		subClassOf = "rdfs:subPropertyOf"
+	}
+	t.add(subj, "rdf:type", iriTerm(t.namespace, typ))
+	t.add(subj, "oboInOwl:id", t.literal(s.id, "xsd:string"))
+	if s.name != "" {
+		t.add(subj, "rdfs:label", t.literal(s.name, "xsd:string"))
+	}
+	namespace := s.namespace
+	if namespace == "" && s.kind == "Term" {
+		namespace = t.doc.defaultNamespace
+	}
+	if namespace != "" {
+		t.add(subj, "oboInOwl:hasOBONamespace", t.literal(namespace, "xsd:string"))
+	}
+	if s.def != "" {
+		def := t.literal(s.def, "xsd:string")
+		t.add(subj, "obo:IAO_0000115", def)
+		t.axiom(subj, "obo:IAO_0000115", def, s.defXrefs)
+	}
+	for _, c := range s.comments {
+		t.add(subj, "rdfs:comment", t.literal(c, "xsd:string"))
+	}
+	for _, sub := range s.subsets {
+		t.add(subj, "oboInOwl:inSubset", iriTerm(t.namespace, oboQName(sub, t.doc.ontology)))
+	}
+	for _, syn := range s.synonyms {
+		pred, ok := synonymPredicates[syn.scope]
+		if !ok {
+			return fmt.Errorf("gogo: obo: %s: invalid synonym scope: %q", s.id, syn.scope)
+		}
+		text := t.literal(syn.text, "xsd:string")
+		t.add(subj, pred, text)
+		t.axiom(subj, pred, text, syn.xrefs)
+	}
+	for _, x := range s.xrefs {
+		t.add(subj, "oboInOwl:hasDbXref", t.literal(x, "xsd:string"))
+	}
+	for _, id := range s.altIDs {
+		t.add(subj, "oboInOwl:hasAlternativeId", t.literal(id, "xsd:string"))
+	}
+
+	for _, id := range s.isA {
+		obj, err := t.entity(id)
+		if err != nil {
+			return err
+		}
+		t.add(subj, subClassOf, obj)
+	}
+	for _, r := range s.relationships {
+		res, err := t.restriction(subj, r)
+		if err != nil {
+			return err
+		}
+		t.add(subj, "rdfs:subClassOf", res)
+	}
+	for _, id := range s.equivalentTo {
+		obj, err := t.entity(id)
+		if err != nil {
+			return err
+		}
+		t.add(subj, "owl:equivalentClass", obj)
+	}
+	if len(s.intersectionOf) != 0 {
+		err := t.intersection(subj, s.intersectionOf)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.obsolete {
+		t.add(subj, "owl:deprecated", t.literal("true", "xsd:boolean"))
+	}
+	for _, id := range s.replacedBy {
+		obj, err := t.entity(id)
+		if err != nil {
+			return err
+		}
+		t.add(subj, "obo:IAO_0100001", obj)
+	}
+	for _, id := range s.consider {
+		t.add(subj, "oboInOwl:consider", t.literal(id, "xsd:string"))
+	}
+	return nil
+}
+
+// synonymPredicates maps OBO synonym scopes to their predicates.
+var synonymPredicates = map[string]string{
+	"EXACT":   "oboInOwl:hasExactSynonym",
+	"RELATED": "oboInOwl:hasRelatedSynonym",
+	"NARROW":  "oboInOwl:hasNarrowSynonym",
+	"BROAD":   "oboInOwl:hasBroadSynonym",
+}
+
+// restriction adds the statements for an owl:someValuesFrom restriction
+// on subj and returns the restriction's blank node.
+func (t *oboTranslator) restriction(subj string, r relationship) (string, error) {
+	prop, err := t.entity(r.rel)
+	if err != nil {
+		return "", err
+	}
+	obj, err := t.entity(r.target)
+	if err != nil {
+		return "", err
+	}
+	res := blank(subj, prop, obj)
+	t.add(res, "rdf:type", iriTerm(t.namespace, "owl:Restriction"))
+	t.add(res, "owl:onProperty", prop)
+	t.add(res, "owl:someValuesFrom", obj)
+	return res, nil
+}
+
+// intersection adds the statements for an owl:equivalentClass intersection
+// of the genus and differentia in rels.
+func (t *oboTranslator) intersection(subj string, rels []relationship) error {
+	class := blank(subj, "intersection_of")
+	t.add(subj, "owl:equivalentClass", class)
+	t.add(class, "rdf:type", iriTerm(t.namespace, "owl:Class"))
+	list := blank(subj, "intersection_of", "0")
+	t.add(class, "owl:intersectionOf", list)
+	for i, r := range rels {
+		var (
+			member string
+			err    error
+		)
+		if r.rel == "" {
+			member, err = t.entity(r.target)
+		} else {
+			member, err = t.restriction(class, r)
+		}
+		if err != nil {
+			return err
+		}
+		t.add(list, "rdf:first", member)
+		rest := iriTerm(t.namespace, "rdf:nil")
+		if i < len(rels)-1 {
+			rest = blank(subj, "intersection_of", fmt.Sprint(i+1))
+		}
+		t.add(list, "rdf:rest", rest)
+		list = rest
+	}
+	return nil
+}
+
+// axiom adds the statements for an owl:Axiom annotating the statement
+// with the given subject, predicate and object with the xrefs. If there
+// are no xrefs, axiom is a no-op.
+func (t *oboTranslator) axiom(subj, pred, obj string, xrefs []string) {
+	if len(xrefs) == 0 {
+		return
+	}
+	ax := blank(subj, pred, obj, "axiom")
+	t.add(ax, "rdf:type", iriTerm(t.namespace, "owl:Axiom"))
+	t.add(ax, "owl:annotatedSource", subj)
+	t.add(ax, "owl:annotatedProperty", iriTerm(t.namespace, pred))
+	t.add(ax, "owl:annotatedTarget", obj)
+	for _, x := range xrefs {
+		t.add(ax, "oboInOwl:hasDbXref", t.literal(x, "xsd:string"))
+	}
+}
+
+// entity returns the IRI term value for the OBO identifier id.
+func (t *oboTranslator) entity(id string) (string, error) {
+	if rel, ok := t.relations[id]; ok {
+		id = rel
+	}
+	term, err := rdf.NewIRITerm(expand(t.namespace, oboQName(id, t.doc.ontology)))
+	if err != nil {
+		return "", fmt.Errorf("gogo: obo: invalid identifier %q: %w", id, err)
+	}
+	return term.Value, nil
+}
+
+// literal returns the RDF literal term value for text with the datatype
+// given by the qualified name typ.
+func (t *oboTranslator) literal(text, typ string) string {
+	term, err := rdf.NewLiteralTerm(text, expand(t.namespace, typ))
+	if err != nil {
+		panic(fmt.Sprintf("gogo: invalid literal datatype %q: %v", typ, err))
+	}
+	return term.Value
+}
+
+// add adds a statement with the given subject and object term values and
+// the predicate with the qualified name pred to the translator.
+func (t *oboTranslator) add(subj, pred, obj string) {
+	t.statements = append(t.statements, &rdf.Statement{
+		Subject:   rdf.Term{Value: subj},
+		Predicate: rdf.Term{Value: iriTerm(t.namespace, pred)},
+		Object:    rdf.Term{Value: obj},
+	})
+}
+
+// blank returns a blank node term value with a label derived from parts.
+func blank(parts ...string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("_:b%016x", h.Sum64())
+}
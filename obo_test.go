@@ -0,0 +1,271 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kortschak/gogo"
+)
+
+const testOBO = `format-version: 1.4
+ontology: go
+default-namespace: gene_ontology
+
+[Term]
+id: GO:0008150
+name: biological_process
+namespace: biological_process
+alt_id: GO:0000004
+def: "A biological process is the execution of a genetically-encoded biological module or program." [GOC:pdt]
+comment: Note that, in addition to forming the root of the biological process ontology...
+synonym: "biological process" EXACT []
+synonym: "physiological process" RELATED [GOC:mah, Reactome:R-HSA-1]
+
+[Term]
+id: GO:0003674
+name: molecular_function
+namespace: molecular_function
+
+[Term]
+id: GO:0005575
+name: cellular_component
+namespace: cellular_component
+
+[Term]
+id: GO:0009987
+name: cellular process
+namespace: biological_process
+is_a: GO:0008150 ! biological_process
+
+[Term]
+id: GO:0007165
+name: signal transduction
+namespace: biological_process
+xref: Wikipedia:Signal_transduction
+is_a: GO:0009987 ! cellular process
+relationship: part_of GO:0007154 ! cell communication
+
+[Term]
+id: GO:0007154
+name: cell communication
+namespace: biological_process
+is_a: GO:0009987 ! cellular process
+
+[Term]
+id: GO:0000001
+name: obsolete thing
+namespace: biological_process
+is_obsolete: true
+replaced_by: GO:0007154
+consider: GO:0007165
+
+[Term]
+id: GO:0023052
+name: signaling
+namespace: biological_process
+intersection_of: GO:0008150 ! biological_process
+intersection_of: part_of GO:0007154 ! cell communication
+is_a: GO:0008150 ! biological_process {source="GOC:xyz"}
+
+[Typedef]
+id: part_of
+name: part of
+namespace: external
+xref: BFO:0000050
+is_transitive: true
+`
+
+func TestLoadOBO(t *testing.T) {
+	g := gogo.NewGraph()
+	stats, err := g.LoadOBO(strings.NewReader(testOBO), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Rejected != 0 {
+		t.Errorf("unexpected rejections: %v", stats.Errors)
+	}
+
+	wantStatements := []string{
+		`<http://purl.obolibrary.org/obo/GO_0008150> <http://www.w3.org/2000/01/rdf-schema#label> "biological_process"^^<http://www.w3.org/2001/XMLSchema#string> .`,
+		`<http://purl.obolibrary.org/obo/GO_0008150> <http://www.geneontology.org/formats/oboInOwl#hasAlternativeId> "GO:0000004"^^<http://www.w3.org/2001/XMLSchema#string> .`,
+		`<http://purl.obolibrary.org/obo/GO_0008150> <http://www.geneontology.org/formats/oboInOwl#hasExactSynonym> "biological process"^^<http://www.w3.org/2001/XMLSchema#string> .`,
+		`<http://purl.obolibrary.org/obo/GO_0008150> <http://purl.obolibrary.org/obo/IAO_0000115> "A biological process is the execution of a genetically-encoded biological module or program."^^<http://www.w3.org/2001/XMLSchema#string> .`,
+		`<http://purl.obolibrary.org/obo/GO_0009987> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <http://purl.obolibrary.org/obo/GO_0008150> .`,
+		`<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2002/07/owl#deprecated> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .`,
+		`<http://purl.obolibrary.org/obo/GO_0000001> <http://purl.obolibrary.org/obo/IAO_0100001> <http://purl.obolibrary.org/obo/GO_0007154> .`,
+		`<http://purl.obolibrary.org/obo/GO_0000001> <http://www.geneontology.org/formats/oboInOwl#consider> "GO:0007165"^^<http://www.w3.org/2001/XMLSchema#string> .`,
+		`<http://purl.obolibrary.org/obo/BFO_0000050> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#ObjectProperty> .`,
+	}
+	got := make(map[string]bool)
+	it := g.AllStatements()
+	for it.Next() {
+		got[it.Statement().String()] = true
+	}
+	for _, s := range wantStatements {
+		if !got[s] {
+			t.Errorf("missing statement: %s", s)
+		}
+	}
+
+	var roots []string
+	for _, r := range g.Roots(true) {
+		roots = append(roots, r.Value)
+	}
+	sort.Strings(roots)
+	wantRoots := []string{
+		"<http://purl.obolibrary.org/obo/GO_0003674>",
+		"<http://purl.obolibrary.org/obo/GO_0005575>",
+		"<http://purl.obolibrary.org/obo/GO_0008150>",
+	}
+	if !equalStrings(roots, wantRoots) {
+		t.Errorf("unexpected roots:\ngot: %v\nwant:%v", roots, wantRoots)
+	}
+
+	bp, ok := g.TermFor("<http://purl.obolibrary.org/obo/GO_0008150>")
+	if !ok {
+		t.Fatal("missing biological_process term")
+	}
+	var desc []string
+	for _, d := range g.DescendantsOf(bp) {
+		desc = append(desc, d.Term.Value)
+	}
+	sort.Strings(desc)
+	wantDesc := []string{
+		"<http://purl.obolibrary.org/obo/GO_0007154>",
+		"<http://purl.obolibrary.org/obo/GO_0007165>",
+		"<http://purl.obolibrary.org/obo/GO_0009987>",
+		"<http://purl.obolibrary.org/obo/GO_0023052>",
+	}
+	if !equalStrings(desc, wantDesc) {
+		t.Errorf("unexpected descendants:\ngot: %v\nwant:%v", desc, wantDesc)
+	}
+
+	st, ok := g.TermFor("<http://purl.obolibrary.org/obo/GO_0007165>")
+	if !ok {
+		t.Fatal("missing signal transduction term")
+	}
+	yes, depth := g.IsDescendantOf(bp, st)
+	if !yes || depth != 2 {
+		t.Errorf("unexpected descendancy: got:%t %d want:true 2", yes, depth)
+	}
+}
+
+func TestLoadOBOAxioms(t *testing.T) {
+	g := gogo.NewGraph()
+	_, err := g.LoadOBO(strings.NewReader(testOBO), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const (
+		owl      = "http://www.w3.org/2002/07/owl#"
+		xsd      = "^^<http://www.w3.org/2001/XMLSchema#string>"
+		hasXref  = "<http://www.geneontology.org/formats/oboInOwl#hasDbXref>"
+		property = "<" + owl + "annotatedProperty>"
+		target   = "<" + owl + "annotatedTarget>"
+	)
+	axioms := make(map[string]map[string][]string)
+	it := g.AllStatements()
+	for it.Next() {
+		s := it.Statement()
+		if !strings.HasPrefix(s.Subject.Value, "_:") {
+			continue
+		}
+		ax, ok := axioms[s.Subject.Value]
+		if !ok {
+			ax = make(map[string][]string)
+			axioms[s.Subject.Value] = ax
+		}
+		ax[s.Predicate.Value] = append(ax[s.Predicate.Value], s.Object.Value)
+	}
+
+	got := make(map[string][]string)
+	for _, ax := range axioms {
+		if len(ax[target]) != 1 || len(ax[property]) != 1 {
+			continue
+		}
+		xrefs := ax[hasXref]
+		sort.Strings(xrefs)
+		got[ax[property][0]+" "+ax[target][0]] = xrefs
+	}
+	want := map[string][]string{
+		`<http://purl.obolibrary.org/obo/IAO_0000115> "A biological process is the execution of a genetically-encoded biological module or program."` + xsd: {
+			`"GOC:pdt"` + xsd,
+		},
+		`<http://www.geneontology.org/formats/oboInOwl#hasRelatedSynonym> "physiological process"` + xsd: {
+			`"GOC:mah"` + xsd,
+			`"Reactome:R-HSA-1"` + xsd,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected axioms:\ngot: %v\nwant:%v", got, want)
+	}
+}
+
+func TestLoadOBOLocal(t *testing.T) {
+	g, _, err := graphFromReader(strings.NewReader(`<obo:GO_0008150> <rdfs:label> "biological_process" .`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := g.LoadOBO(strings.NewReader(testOBO), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Rejected != 0 {
+		t.Errorf("unexpected rejections: %v", stats.Errors)
+	}
+	_, ok := g.TermFor("<obo:GO_0007165>")
+	if !ok {
+		t.Error("missing locally namespaced signal transduction term")
+	}
+	_, ok = g.TermFor("<rdfs:subClassOf>")
+	if !ok {
+		t.Error("missing locally namespaced subClassOf predicate")
+	}
+}
+
+func TestLoadOBOIdempotent(t *testing.T) {
+	g := gogo.NewGraph()
+	first, err := g.LoadOBO(strings.NewReader(testOBO), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := countStatements(g)
+	if n != first.Accepted {
+		t.Errorf("unexpected number of statements: got:%d want:%d", n, first.Accepted)
+	}
+	_, err = g.LoadOBO(strings.NewReader(testOBO), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := countStatements(g); got != n {
+		t.Errorf("unexpected number of statements after reload: got:%d want:%d", got, n)
+	}
+}
+
+func countStatements(g *gogo.Graph) int {
+	seen := make(map[string]bool)
+	it := g.AllStatements()
+	for it.Next() {
+		seen[it.Statement().String()] = true
+	}
+	return len(seen)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,509 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// OBO Graphs JSON types.
+//
+// See https://github.com/geneontology/obographs for details.
+type (
+	ogDocument struct {
+		Graphs []ogGraph `json:"graphs"`
+	}
+
+	ogGraph struct {
+		ID                      string                `json:"id,omitempty"`
+		Nodes                   []ogNode              `json:"nodes"`
+		Edges                   []ogEdge              `json:"edges"`
+		EquivalentNodesSets     []ogEquivalentNodeSet `json:"equivalentNodesSets,omitempty"`
+		LogicalDefinitionAxioms []ogLogicalDefinition `json:"logicalDefinitionAxioms,omitempty"`
+	}
+
+	ogNode struct {
+		ID   string  `json:"id"`
+		Lbl  string  `json:"lbl,omitempty"`
+		Type string  `json:"type,omitempty"`
+		Meta *ogMeta `json:"meta,omitempty"`
+	}
+
+	ogMeta struct {
+		Definition          *ogDefinition     `json:"definition,omitempty"`
+		Comments            []string          `json:"comments,omitempty"`
+		Subsets             []string          `json:"subsets,omitempty"`
+		Synonyms            []ogSynonym       `json:"synonyms,omitempty"`
+		Xrefs               []ogXref          `json:"xrefs,omitempty"`
+		BasicPropertyValues []ogPropertyValue `json:"basicPropertyValues,omitempty"`
+		Deprecated          bool              `json:"deprecated,omitempty"`
+	}
+
+	ogDefinition struct {
+		Val   string   `json:"val"`
+		Xrefs []string `json:"xrefs,omitempty"`
+	}
+
+	ogSynonym struct {
+		Pred  string   `json:"pred"`
+		Val   string   `json:"val"`
+		Xrefs []string `json:"xrefs,omitempty"`
+	}
+
+	ogXref struct {
+		Val string `json:"val"`
+	}
+
+	ogPropertyValue struct {
+		Pred string `json:"pred"`
+		Val  string `json:"val"`
+	}
+
+	ogEdge struct {
+		Sub  string `json:"sub"`
+		Pred string `json:"pred"`
+		Obj  string `json:"obj"`
+	}
+
+	ogEquivalentNodeSet struct {
+		NodeIDs []string `json:"nodeIds"`
+	}
+
+	ogLogicalDefinition struct {
+		DefinedClassID string          `json:"definedClassId"`
+		GenusIDs       []string        `json:"genusIds,omitempty"`
+		Restrictions   []ogRestriction `json:"restrictions,omitempty"`
+	}
+
+	ogRestriction struct {
+		PropertyID string `json:"propertyId"`
+		FillerID   string `json:"fillerId"`
+	}
+)
+
+// OBO Graphs basic property value predicates.
+const (
+	ogNamespace     = "http://www.geneontology.org/formats/oboInOwl#hasOBONamespace"
+	ogAlternativeID = "http://www.geneontology.org/formats/oboInOwl#hasAlternativeId"
+	ogConsider      = "http://www.geneontology.org/formats/oboInOwl#consider"
+	ogReplacedBy    = "http://purl.obolibrary.org/obo/IAO_0100001"
+)
+
+// synonymScopes maps OBO Graphs synonym predicates to OBO synonym scopes.
+var synonymScopes = map[string]string{
+	"hasExactSynonym":   "EXACT",
+	"hasRelatedSynonym": "RELATED",
+	"hasNarrowSynonym":  "NARROW",
+	"hasBroadSynonym":   "BROAD",
+}
+
+// LoadOBOGraph reads an OBO Graphs JSON document from r and adds the classes
+// and properties of all the graphs in the document to the graph according
+// to opts. Input compressed with gzip is detected and decompressed.
+//
+// Nodes, edges, logical definition axioms and equivalent node sets are
+// translated to statements in the same way as the equivalent OBO 1.4 tags
+// are by LoadOBO, including definition and synonym xrefs.
+func (g *Graph) LoadOBOGraph(r io.Reader, opts *LoadOptions) (LoadStats, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return LoadStats{}, err
+	}
+	var og ogDocument
+	err = json.NewDecoder(r).Decode(&og)
+	if err != nil {
+		return LoadStats{}, err
+	}
+
+	namespace := g.namespace
+	if namespace != local {
+		namespace = global
+	}
+	var statements []*rdf.Statement
+	for _, graph := range og.Graphs {
+		s, err := graph.document().statements(namespace)
+		if err != nil {
+			return LoadStats{}, err
+		}
+		statements = append(statements, s...)
+	}
+	return g.load(sliceStatements(statements), opts)
+}
+
+// document returns an OBO document corresponding to the receiver.
+func (og *ogGraph) document() *oboDocument {
+	doc := oboDocument{ontology: ontologyName(og.ID)}
+
+	stanzas := make(map[string]*stanza)
+	stanzaFor := func(iri string) *stanza {
+		s, ok := stanzas[iri]
+		if !ok {
+			s = &stanza{kind: "Term", id: oboID(iri)}
+			stanzas[iri] = s
+			doc.stanzas = append(doc.stanzas, s)
+		}
+		return s
+	}
+
+	for _, n := range og.Nodes {
+		switch n.Type {
+		case "CLASS", "":
+		case "PROPERTY":
+		default:
+			continue
+		}
+		s := stanzaFor(n.ID)
+		if n.Type == "PROPERTY" {
+			s.kind = "Typedef"
+		}
+		s.name = n.Lbl
+		if n.Meta == nil {
+			continue
+		}
+		m := n.Meta
+		if m.Definition != nil {
+			s.def = m.Definition.Val
+			s.defXrefs = m.Definition.Xrefs
+		}
+		s.comments = m.Comments
+		s.subsets = m.Subsets
+		for _, syn := range m.Synonyms {
+			scope, ok := synonymScopes[syn.Pred]
+			if !ok {
+				scope = "RELATED"
+			}
+			s.synonyms = append(s.synonyms, synonym{text: syn.Val, scope: scope, xrefs: syn.Xrefs})
+		}
+		for _, x := range m.Xrefs {
+			s.xrefs = append(s.xrefs, x.Val)
+		}
+		for _, pv := range m.BasicPropertyValues {
+			switch pv.Pred {
+			case ogNamespace:
+				s.namespace = pv.Val
+			case ogAlternativeID:
+				s.altIDs = append(s.altIDs, pv.Val)
+			case ogConsider:
+				s.consider = append(s.consider, pv.Val)
+			case ogReplacedBy:
+				s.replacedBy = append(s.replacedBy, oboID(pv.Val))
+			}
+		}
+		s.obsolete = m.Deprecated
+	}
+
+	for _, e := range og.Edges {
+		s := stanzaFor(e.Sub)
+		switch e.Pred {
+		case "is_a", "subPropertyOf":
+			s.isA = append(s.isA, oboID(e.Obj))
+		default:
+			s.relationships = append(s.relationships, relationship{rel: oboID(e.Pred), target: oboID(e.Obj)})
+		}
+	}
+
+	for _, ld := range og.LogicalDefinitionAxioms {
+		s := stanzaFor(ld.DefinedClassID)
+		for _, id := range ld.GenusIDs {
+			s.intersectionOf = append(s.intersectionOf, relationship{target: oboID(id)})
+		}
+		for _, r := range ld.Restrictions {
+			s.intersectionOf = append(s.intersectionOf, relationship{rel: oboID(r.PropertyID), target: oboID(r.FillerID)})
+		}
+	}
+
+	for _, set := range og.EquivalentNodesSets {
+		for i, a := range set.NodeIDs {
+			for _, b := range set.NodeIDs[i+1:] {
+				s := stanzaFor(a)
+				s.equivalentTo = append(s.equivalentTo, oboID(b))
+			}
+		}
+	}
+
+	return &doc
+}
+
+// ontologyName returns the ontology name for an OBO Foundry ontology
+// IRI, "go" for "http://purl.obolibrary.org/obo/go.owl".
+func ontologyName(iri string) string {
+	obo := prefixes["obo"]
+	if !strings.HasPrefix(iri, obo) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(iri[len(obo):], ".owl"), ".json")
+}
+
+// oboID returns the OBO identifier for an OBO Foundry term IRI, "GO:0008150"
+// for "http://purl.obolibrary.org/obo/GO_0008150". If iri is not an OBO
+// Foundry term IRI it is returned unaltered.
+func oboID(iri string) string {
+	obo := prefixes["obo"]
+	if !strings.HasPrefix(iri, obo) {
+		return iri
+	}
+	id := iri[len(obo):]
+	i := strings.Index(id, "_")
+	if i <= 0 || strings.ContainsAny(id, "#/") {
+		return iri
+	}
+	return id[:i] + ":" + id[i+1:]
+}
+
+// WriteOBOGraph writes the classes and properties of the graph to w as an
+// OBO Graphs JSON document holding a single graph with the given id. Term
+// IRIs are written in their global form. Nodes, edges and axioms are sorted
+// so that the output is deterministic.
+func (g *Graph) WriteOBOGraph(w io.Writer, id string) error {
+	og := ogGraph{ID: id, Nodes: []ogNode{}, Edges: []ogEdge{}}
+
+	class := iriTerm(g.namespace, "owl:Class")
+	property := iriTerm(g.namespace, "owl:ObjectProperty")
+	for _, n := range g.nodes {
+		t, ok := n.(rdf.Term)
+		if !ok || !isIRI(t) {
+			continue
+		}
+		var nodeType string
+		for _, o := range g.objects(t, "rdf:type") {
+			switch o.Value {
+			case class:
+				nodeType = "CLASS"
+			case property:
+				nodeType = "PROPERTY"
+			}
+		}
+		if nodeType == "" {
+			continue
+		}
+
+		node := ogNode{ID: g.globalIRI(t), Type: nodeType, Meta: g.ogMeta(t)}
+		if lbl := g.literals(t, "rdfs:label"); len(lbl) != 0 {
+			node.Lbl = lbl[0]
+		}
+		og.Nodes = append(og.Nodes, node)
+
+		pred := "is_a"
+		if nodeType == "PROPERTY" {
+			pred = "subPropertyOf"
+			for _, o := range g.objects(t, "rdfs:subPropertyOf") {
+				if isIRI(o) {
+					og.Edges = append(og.Edges, ogEdge{Sub: node.ID, Pred: pred, Obj: g.globalIRI(o)})
+				}
+			}
+			continue
+		}
+		for _, o := range g.objects(t, "rdfs:subClassOf") {
+			if isIRI(o) {
+				og.Edges = append(og.Edges, ogEdge{Sub: node.ID, Pred: pred, Obj: g.globalIRI(o)})
+				continue
+			}
+			prop, filler, ok := g.someValuesFrom(o)
+			if ok {
+				og.Edges = append(og.Edges, ogEdge{Sub: node.ID, Pred: g.globalIRI(prop), Obj: g.globalIRI(filler)})
+			}
+		}
+		for _, o := range g.objects(t, "owl:equivalentClass") {
+			if isIRI(o) {
+				ids := []string{node.ID, g.globalIRI(o)}
+				sort.Strings(ids)
+				og.EquivalentNodesSets = append(og.EquivalentNodesSets, ogEquivalentNodeSet{NodeIDs: ids})
+				continue
+			}
+			if ld, ok := g.ogLogicalDefinition(node.ID, o); ok {
+				og.LogicalDefinitionAxioms = append(og.LogicalDefinitionAxioms, ld)
+			}
+		}
+	}
+
+	sort.Slice(og.Nodes, func(i, j int) bool { return og.Nodes[i].ID < og.Nodes[j].ID })
+	sort.Slice(og.Edges, func(i, j int) bool {
+		a, b := og.Edges[i], og.Edges[j]
+		if a.Sub != b.Sub {
+			return a.Sub < b.Sub
+		}
+		if a.Pred != b.Pred {
+			return a.Pred < b.Pred
+		}
+		return a.Obj < b.Obj
+	})
+	sort.Slice(og.EquivalentNodesSets, func(i, j int) bool {
+		return strings.Join(og.EquivalentNodesSets[i].NodeIDs, " ") < strings.Join(og.EquivalentNodesSets[j].NodeIDs, " ")
+	})
+	sort.Slice(og.LogicalDefinitionAxioms, func(i, j int) bool {
+		return og.LogicalDefinitionAxioms[i].DefinedClassID < og.LogicalDefinitionAxioms[j].DefinedClassID
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ogDocument{Graphs: []ogGraph{og}})
+}
+
+// ogMeta returns the OBO Graphs metadata for t, or nil if t has none.
+func (g *Graph) ogMeta(t rdf.Term) *ogMeta {
+	var m ogMeta
+	if def := g.literals(t, "obo:IAO_0000115"); len(def) != 0 {
+		m.Definition = &ogDefinition{Val: def[0], Xrefs: g.axiomXrefs(t, "obo:IAO_0000115", def[0])}
+	}
+	m.Comments = sorted(g.literals(t, "rdfs:comment"))
+	for _, s := range g.objects(t, "oboInOwl:inSubset") {
+		m.Subsets = append(m.Subsets, g.globalIRI(s))
+	}
+	sort.Strings(m.Subsets)
+	for _, pred := range []string{"hasExactSynonym", "hasRelatedSynonym", "hasNarrowSynonym", "hasBroadSynonym"} {
+		for _, syn := range sorted(g.literals(t, "oboInOwl:"+pred)) {
+			m.Synonyms = append(m.Synonyms, ogSynonym{Pred: pred, Val: syn, Xrefs: g.axiomXrefs(t, "oboInOwl:"+pred, syn)})
+		}
+	}
+	for _, x := range sorted(g.literals(t, "oboInOwl:hasDbXref")) {
+		m.Xrefs = append(m.Xrefs, ogXref{Val: x})
+	}
+	for _, ns := range sorted(g.literals(t, "oboInOwl:hasOBONamespace")) {
+		m.BasicPropertyValues = append(m.BasicPropertyValues, ogPropertyValue{Pred: ogNamespace, Val: ns})
+	}
+	for _, id := range sorted(g.literals(t, "oboInOwl:hasAlternativeId")) {
+		m.BasicPropertyValues = append(m.BasicPropertyValues, ogPropertyValue{Pred: ogAlternativeID, Val: id})
+	}
+	var replacedBy []string
+	for _, r := range g.objects(t, "obo:IAO_0100001") {
+		if isIRI(r) {
+			replacedBy = append(replacedBy, g.globalIRI(r))
+		} else if text, _, kind, err := r.Parts(); err == nil && kind == rdf.Literal {
+			replacedBy = append(replacedBy, text)
+		}
+	}
+	for _, r := range sorted(replacedBy) {
+		m.BasicPropertyValues = append(m.BasicPropertyValues, ogPropertyValue{Pred: ogReplacedBy, Val: r})
+	}
+	for _, c := range sorted(g.literals(t, "oboInOwl:consider")) {
+		m.BasicPropertyValues = append(m.BasicPropertyValues, ogPropertyValue{Pred: ogConsider, Val: c})
+	}
+	for _, d := range g.literals(t, "owl:deprecated") {
+		if d == "true" {
+			m.Deprecated = true
+		}
+	}
+
+	if m.Definition == nil && m.Comments == nil && m.Subsets == nil && m.Synonyms == nil &&
+		m.Xrefs == nil && m.BasicPropertyValues == nil && !m.Deprecated {
+		return nil
+	}
+	return &m
+}
+
+// axiomXrefs returns the sorted xrefs held by the owl:Axiom annotations of
+// the statement with the subject t, the predicate with the qualified name
+// pred and the literal object text.
+func (g *Graph) axiomXrefs(t rdf.Term, pred, text string) []string {
+	p := iriTerm(g.namespace, pred)
+	var xrefs []string
+	for _, ax := range g.subjects(t, "owl:annotatedSource") {
+		var isPred bool
+		for _, o := range g.objects(ax, "owl:annotatedProperty") {
+			if o.Value == p {
+				isPred = true
+				break
+			}
+		}
+		if !isPred {
+			continue
+		}
+		for _, target := range g.literals(ax, "owl:annotatedTarget") {
+			if target == text {
+				xrefs = append(xrefs, g.literals(ax, "oboInOwl:hasDbXref")...)
+				break
+			}
+		}
+	}
+	return sorted(xrefs)
+}
+
+// ogLogicalDefinition returns the logical definition of the class with the
+// given id from its equivalent class expression, expr, if expr is an
+// owl:intersectionOf of named classes and owl:someValuesFrom restrictions.
+func (g *Graph) ogLogicalDefinition(id string, expr rdf.Term) (ogLogicalDefinition, bool) {
+	ld := ogLogicalDefinition{DefinedClassID: id}
+	lists := g.objects(expr, "owl:intersectionOf")
+	if len(lists) != 1 {
+		return ld, false
+	}
+	members, ok := g.list(lists[0])
+	if !ok {
+		return ld, false
+	}
+	for _, m := range members {
+		if isIRI(m) {
+			ld.GenusIDs = append(ld.GenusIDs, g.globalIRI(m))
+			continue
+		}
+		prop, filler, ok := g.someValuesFrom(m)
+		if !ok {
+			return ld, false
+		}
+		ld.Restrictions = append(ld.Restrictions, ogRestriction{PropertyID: g.globalIRI(prop), FillerID: g.globalIRI(filler)})
+	}
+	return ld, true
+}
+
+// someValuesFrom returns the property and filler of the owl:someValuesFrom
+// restriction r if r is a restriction on a named class.
+func (g *Graph) someValuesFrom(r rdf.Term) (prop, filler rdf.Term, ok bool) {
+	props := g.objects(r, "owl:onProperty")
+	fillers := g.objects(r, "owl:someValuesFrom")
+	if len(props) != 1 || len(fillers) != 1 || !isIRI(fillers[0]) {
+		return prop, filler, false
+	}
+	return props[0], fillers[0], true
+}
+
+// list returns the members of the RDF collection with the head node l.
+func (g *Graph) list(l rdf.Term) ([]rdf.Term, bool) {
+	nilList := iriTerm(g.namespace, "rdf:nil")
+	seen := make(map[int64]bool)
+	var members []rdf.Term
+	for l.Value != nilList {
+		if seen[l.UID] {
+			return nil, false
+		}
+		seen[l.UID] = true
+		first := g.objects(l, "rdf:first")
+		rest := g.objects(l, "rdf:rest")
+		if len(first) != 1 || len(rest) != 1 {
+			return nil, false
+		}
+		members = append(members, first[0])
+		l = rest[0]
+	}
+	return members, true
+}
+
+// globalIRI returns the global IRI of the IRI term t without the enclosing
+// angle brackets.
+func (g *Graph) globalIRI(t rdf.Term) string {
+	text, _, _, err := t.Parts()
+	if err != nil {
+		return strings.TrimSuffix(strings.TrimPrefix(t.Value, "<"), ">")
+	}
+	if g.namespace == local {
+		return expand(global, text)
+	}
+	return text
+}
+
+// isIRI returns whether t is an IRI term.
+func isIRI(t rdf.Term) bool {
+	return strings.HasPrefix(t.Value, "<")
+}
+
+// sorted returns s after sorting it.
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
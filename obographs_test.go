@@ -0,0 +1,247 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/diff"
+	"github.com/pkg/diff/write"
+
+	"github.com/kortschak/gogo"
+)
+
+const testOBOGraph = `{
+  "graphs": [
+    {
+      "id": "http://purl.obolibrary.org/obo/go.owl",
+      "nodes": [
+        {
+          "id": "http://purl.obolibrary.org/obo/GO_0008150",
+          "lbl": "biological_process",
+          "type": "CLASS",
+          "meta": {
+            "definition": {"val": "A biological process.", "xrefs": ["GOC:pdt"]},
+            "synonyms": [
+              {"pred": "hasExactSynonym", "val": "biological process"},
+              {"pred": "hasRelatedSynonym", "val": "physiological process", "xrefs": ["GOC:mah", "Reactome:R-HSA-1"]}
+            ],
+            "basicPropertyValues": [
+              {"pred": "http://www.geneontology.org/formats/oboInOwl#hasOBONamespace", "val": "biological_process"},
+              {"pred": "http://www.geneontology.org/formats/oboInOwl#hasAlternativeId", "val": "GO:0000004"}
+            ]
+          }
+        },
+        {
+          "id": "http://purl.obolibrary.org/obo/GO_0009987",
+          "lbl": "cellular process",
+          "type": "CLASS"
+        },
+        {
+          "id": "http://purl.obolibrary.org/obo/GO_0007154",
+          "lbl": "cell communication",
+          "type": "CLASS",
+          "meta": {"xrefs": [{"val": "Wikipedia:Cell_signaling"}]}
+        },
+        {
+          "id": "http://purl.obolibrary.org/obo/GO_0023052",
+          "lbl": "signaling",
+          "type": "CLASS"
+        },
+        {
+          "id": "http://purl.obolibrary.org/obo/GO_0000001",
+          "lbl": "obsolete thing",
+          "type": "CLASS",
+          "meta": {
+            "deprecated": true,
+            "basicPropertyValues": [
+              {"pred": "http://purl.obolibrary.org/obo/IAO_0100001", "val": "GO:0007154"}
+            ]
+          }
+        },
+        {
+          "id": "http://purl.obolibrary.org/obo/BFO_0000050",
+          "lbl": "part of",
+          "type": "PROPERTY"
+        }
+      ],
+      "edges": [
+        {"sub": "http://purl.obolibrary.org/obo/GO_0009987", "pred": "is_a", "obj": "http://purl.obolibrary.org/obo/GO_0008150"},
+        {"sub": "http://purl.obolibrary.org/obo/GO_0007154", "pred": "is_a", "obj": "http://purl.obolibrary.org/obo/GO_0009987"},
+        {"sub": "http://purl.obolibrary.org/obo/GO_0023052", "pred": "is_a", "obj": "http://purl.obolibrary.org/obo/GO_0008150"},
+        {"sub": "http://purl.obolibrary.org/obo/GO_0023052", "pred": "http://purl.obolibrary.org/obo/BFO_0000050", "obj": "http://purl.obolibrary.org/obo/GO_0007154"}
+      ],
+      "logicalDefinitionAxioms": [
+        {
+          "definedClassId": "http://purl.obolibrary.org/obo/GO_0023052",
+          "genusIds": ["http://purl.obolibrary.org/obo/GO_0008150"],
+          "restrictions": [{"propertyId": "http://purl.obolibrary.org/obo/BFO_0000050", "fillerId": "http://purl.obolibrary.org/obo/GO_0007154"}]
+        }
+      ],
+      "equivalentNodesSets": [
+        {"nodeIds": ["http://purl.obolibrary.org/obo/GO_0009987", "http://purl.obolibrary.org/obo/GO_0099999"]}
+      ]
+    }
+  ]
+}`
+
+func TestOBOGraphRoundTrip(t *testing.T) {
+	g := gogo.NewGraph()
+	stats, err := g.LoadOBOGraph(strings.NewReader(testOBOGraph), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Rejected != 0 {
+		t.Errorf("unexpected rejections: %v", stats.Errors)
+	}
+
+	bp, ok := g.TermFor("<http://purl.obolibrary.org/obo/GO_0008150>")
+	if !ok {
+		t.Fatal("missing biological_process term")
+	}
+	cc, ok := g.TermFor("<http://purl.obolibrary.org/obo/GO_0007154>")
+	if !ok {
+		t.Fatal("missing cell communication term")
+	}
+	yes, depth := g.IsDescendantOf(bp, cc)
+	if !yes || depth != 2 {
+		t.Errorf("unexpected descendancy: got:%t %d want:true 2", yes, depth)
+	}
+
+	var first bytes.Buffer
+	err = g.WriteOBOGraph(&first, "http://purl.obolibrary.org/obo/go.owl")
+	if err != nil {
+		t.Fatalf("unexpected error writing graph: %v", err)
+	}
+
+	var doc struct {
+		Graphs []struct {
+			Nodes []struct {
+				ID   string `json:"id"`
+				Lbl  string `json:"lbl"`
+				Type string `json:"type"`
+			} `json:"nodes"`
+			Edges []struct {
+				Sub  string `json:"sub"`
+				Pred string `json:"pred"`
+				Obj  string `json:"obj"`
+			} `json:"edges"`
+			LogicalDefinitionAxioms []json.RawMessage `json:"logicalDefinitionAxioms"`
+			EquivalentNodesSets     []json.RawMessage `json:"equivalentNodesSets"`
+		} `json:"graphs"`
+	}
+	err = json.Unmarshal(first.Bytes(), &doc)
+	if err != nil {
+		t.Fatalf("unexpected error unmarshaling output: %v", err)
+	}
+	if len(doc.Graphs) != 1 {
+		t.Fatalf("unexpected number of graphs: got:%d want:1", len(doc.Graphs))
+	}
+	og := doc.Graphs[0]
+	if len(og.Nodes) != 6 {
+		t.Errorf("unexpected number of nodes: got:%d want:6", len(og.Nodes))
+	}
+	if len(og.Edges) != 4 {
+		t.Errorf("unexpected number of edges: got:%d want:4", len(og.Edges))
+	}
+	if len(og.LogicalDefinitionAxioms) != 1 {
+		t.Errorf("unexpected number of logical definitions: got:%d want:1", len(og.LogicalDefinitionAxioms))
+	}
+	if len(og.EquivalentNodesSets) != 1 {
+		t.Errorf("unexpected number of equivalent node sets: got:%d want:1", len(og.EquivalentNodesSets))
+	}
+
+	// Check that the output is stable through a
+	// locally namespaced graph.
+	l, _, err := graphFromReader(strings.NewReader(`<obo:GO_0008150> <rdfs:label> "biological_process"^^<xsd:string> .`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = l.LoadOBOGraph(bytes.NewReader(first.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("unexpected error reloading graph: %v", err)
+	}
+	if _, ok := l.TermFor("<obo:GO_0007154>"); !ok {
+		t.Error("missing locally namespaced cell communication term")
+	}
+	var second bytes.Buffer
+	err = l.WriteOBOGraph(&second, "http://purl.obolibrary.org/obo/go.owl")
+	if err != nil {
+		t.Fatalf("unexpected error writing graph: %v", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		var buf bytes.Buffer
+		err := diff.Text("first", "second", first.String(), second.String(), &buf, write.TerminalColor())
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		t.Errorf("unexpected round trip result:\n%s", &buf)
+	}
+}
+
+func TestOBOGraphXrefs(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := gogo.NewGraph()
+		if local {
+			var err error
+			g, _, err = graphFromReader(strings.NewReader(`<obo:GO_0008150> <rdfs:label> "biological_process"^^<xsd:string> .`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		_, err := g.LoadOBOGraph(strings.NewReader(testOBOGraph), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		err = g.WriteOBOGraph(&buf, "http://purl.obolibrary.org/obo/go.owl")
+		if err != nil {
+			t.Fatalf("unexpected error writing graph: %v", err)
+		}
+
+		var doc struct {
+			Graphs []struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Meta struct {
+						Definition struct {
+							Xrefs []string `json:"xrefs"`
+						} `json:"definition"`
+						Synonyms []struct {
+							Val   string   `json:"val"`
+							Xrefs []string `json:"xrefs"`
+						} `json:"synonyms"`
+					} `json:"meta"`
+				} `json:"nodes"`
+			} `json:"graphs"`
+		}
+		err = json.Unmarshal(buf.Bytes(), &doc)
+		if err != nil {
+			t.Fatalf("unexpected error unmarshaling output: %v", err)
+		}
+		got := make(map[string][]string)
+		for _, n := range doc.Graphs[0].Nodes {
+			if n.ID != "http://purl.obolibrary.org/obo/GO_0008150" {
+				continue
+			}
+			got["definition"] = n.Meta.Definition.Xrefs
+			for _, syn := range n.Meta.Synonyms {
+				got[syn.Val] = syn.Xrefs
+			}
+		}
+		want := map[string][]string{
+			"definition":            {"GOC:pdt"},
+			"biological process":    nil,
+			"physiological process": {"GOC:mah", "Reactome:R-HSA-1"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected xrefs for local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// IsObsolete returns whether the term t is marked as deprecated in g by the
+// deprecation predicate of the graph's ontology.
+func (g *Graph) IsObsolete(t rdf.Term) bool {
+	return g.isObsolete(t, g.Ontology())
+}
+
+// isObsolete returns whether the term t is marked as deprecated in g by the
+// deprecation predicate of the ontology o.
+func (g *Graph) isObsolete(t rdf.Term, o Ontology) bool {
+	for _, d := range g.literals(t, o.deprecated()) {
+		if d == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// Replacement returns the term that replaces the obsolete term t. The
+// obo:IAO_0100001 (term replaced by) chain from t is followed until a term
+// that is not obsolete is found. If t is not obsolete, t is returned. If the
+// chain ends at an obsolete term without a replacement, or the chain has a
+// cycle, ok is false. Where a term has more than one replacement, the
+// lexically first is followed.
+func (g *Graph) Replacement(t rdf.Term) (r rdf.Term, ok bool) {
+	seen := make(map[int64]bool)
+	for g.IsObsolete(t) {
+		if seen[t.UID] {
+			return r, false
+		}
+		seen[t.UID] = true
+		next := g.replacedBy(t)
+		if len(next) == 0 {
+			return r, false
+		}
+		t = next[0]
+	}
+	return t, true
+}
+
+// Consider returns the terms suggested by oboInOwl:consider as alternatives
+// to the obsolete term t. Suggested terms that are themselves obsolete are
+// resolved to their replacement, or omitted if they have none. The returned
+// terms are sorted by value.
+func (g *Graph) Consider(t rdf.Term) []rdf.Term {
+	seen := make(map[int64]bool)
+	var terms []rdf.Term
+	for _, c := range g.linked(t, "oboInOwl:consider") {
+		c, ok := g.Replacement(c)
+		if !ok || seen[c.UID] {
+			continue
+		}
+		seen[c.UID] = true
+		terms = append(terms, c)
+	}
+	sortTerms(terms)
+	return terms
+}
+
+// replacedBy returns the direct replacements of t sorted by value.
+func (g *Graph) replacedBy(t rdf.Term) []rdf.Term {
+	terms := g.linked(t, "obo:IAO_0100001")
+	sortTerms(terms)
+	return terms
+}
+
+// linked returns the terms in g that are objects of statements with the
+// subject t and the predicate with the qualified name pred. Objects may be
+// IRIs or literal OBO identifiers. Identifiers that do not correspond to a
+// term in g are ignored.
+func (g *Graph) linked(t rdf.Term, pred string) []rdf.Term {
+	var terms []rdf.Term
+	for _, o := range g.objects(t, pred) {
+		if isIRI(o) {
+			terms = append(terms, o)
+			continue
+		}
+		id, _, kind, err := o.Parts()
+		if err != nil || kind != rdf.Literal {
+			continue
+		}
+		if r, ok := g.TermFor(iriTerm(g.namespace, oboQName(id, ""))); ok {
+			terms = append(terms, r)
+		}
+	}
+	return terms
+}
+
+// sortTerms sorts terms by value.
+func sortTerms(terms []rdf.Term) {
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].Value < terms[j].Value
+	})
+}
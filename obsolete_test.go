@@ -0,0 +1,136 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/kortschak/gogo"
+)
+
+const obsoleteOBO = `format-version: 1.4
+ontology: go
+
+[Term]
+id: GO:0000001
+name: current root
+
+[Term]
+id: GO:0000002
+name: current child
+is_a: GO:0000001
+
+[Term]
+id: GO:0000003
+name: obsolete child
+is_a: GO:0000001
+is_obsolete: true
+replaced_by: GO:0000004
+
+[Term]
+id: GO:0000004
+name: obsolete intermediate
+is_obsolete: true
+replaced_by: GO:0000002
+
+[Term]
+id: GO:0000005
+name: obsolete cycle start
+is_obsolete: true
+replaced_by: GO:0000006
+
+[Term]
+id: GO:0000006
+name: obsolete cycle end
+is_obsolete: true
+replaced_by: GO:0000005
+
+[Term]
+id: GO:0000007
+name: obsolete with suggestions
+is_obsolete: true
+consider: GO:0000002
+consider: GO:0000003
+consider: GO:0000005
+consider: GO:0000001
+`
+
+var replacementTests = []struct {
+	id     string
+	want   string
+	wantOK bool
+}{
+	{id: "0000001", want: "0000001", wantOK: true},
+	{id: "0000003", want: "0000002", wantOK: true},
+	{id: "0000004", want: "0000002", wantOK: true},
+	{id: "0000005", wantOK: false},
+	{id: "0000007", wantOK: false},
+}
+
+func TestReplacement(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, obsoleteOBO, local)
+		for _, test := range replacementTests {
+			term, ok := g.TermFor(goTerm(test.id, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.id)
+			}
+			if g.IsObsolete(term) == (test.id == "0000001") {
+				t.Errorf("unexpected obsolescence for %q local=%t", test.id, local)
+			}
+			got, ok := g.Replacement(term)
+			if ok != test.wantOK {
+				t.Errorf("unexpected ok for %q local=%t: got:%t want:%t", test.id, local, ok, test.wantOK)
+				continue
+			}
+			if ok && got.Value != goTerm(test.want, local) {
+				t.Errorf("unexpected replacement for %q local=%t: got:%s want:%s", test.id, local, got.Value, goTerm(test.want, local))
+			}
+		}
+	}
+}
+
+func TestConsider(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, obsoleteOBO, local)
+		term, ok := g.TermFor(goTerm("0000007", local))
+		if !ok {
+			t.Fatal("missing term")
+		}
+		var got []string
+		for _, c := range g.Consider(term) {
+			got = append(got, c.Value)
+		}
+		want := []string{goTerm("0000001", local), goTerm("0000002", local)}
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected suggestions local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+	}
+}
+
+func TestAncestryExcludeDeprecated(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, obsoleteOBO, local)
+		root, ok := g.TermFor(goTerm("0000001", local))
+		if !ok {
+			t.Fatal("missing term")
+		}
+		for _, exclude := range []bool{false, true} {
+			var got []string
+			for _, d := range (gogo.Ancestry{Graph: g, ExcludeDeprecated: exclude}).DescendantsOf(root) {
+				got = append(got, d.Term.Value)
+			}
+			sort.Strings(got)
+			want := []string{goTerm("0000002", local)}
+			if !exclude {
+				want = append(want, goTerm("0000003", local))
+			}
+			if !equalStrings(got, want) {
+				t.Errorf("unexpected descendants local=%t exclude=%t:\ngot: %v\nwant:%v", local, exclude, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Ontology describes the vocabulary of an OBO Foundry ontology held in
+// a Graph.
+type Ontology struct {
+	// Prefix is the OBO identifier prefix of
+	// the ontology's terms, for example "GO"
+	// or "HP".
+	Prefix string
+
+	// SubClassOf is the qualified name of the
+	// subclass predicate. If SubClassOf is
+	// empty, "rdfs:subClassOf" is used.
+	SubClassOf string
+
+	// Deprecated is the qualified name of the
+	// deprecation predicate. If Deprecated is
+	// empty, "owl:deprecated" is used.
+	Deprecated string
+
+	// Roots holds the OBO identifiers of the
+	// known roots of the ontology.
+	Roots []string
+}
+
+// GO is the Gene Ontology vocabulary. It is the default ontology of a Graph.
+var GO = Ontology{
+	Prefix:     "GO",
+	SubClassOf: "rdfs:subClassOf",
+	Deprecated: "owl:deprecated",
+	Roots: []string{
+		"GO:0003674", // molecular_function
+		"GO:0005575", // cellular_component
+		"GO:0008150", // biological_process
+	},
+}
+
+// SetOntology sets the ontology vocabulary used by g for ancestry, root and
+// obsolescence queries. The default ontology is GO.
+func (g *Graph) SetOntology(o Ontology) {
+	g.ontology = &o
+}
+
+// Ontology returns the ontology vocabulary used by g.
+func (g *Graph) Ontology() Ontology {
+	if g.ontology == nil {
+		return GO
+	}
+	return *g.ontology
+}
+
+// termPrefix returns the term value prefix of the ontology's terms in the
+// given namespace form. If the namespace is not known, ok is false.
+func (o Ontology) termPrefix(namespace int) (prefix string, ok bool) {
+	if namespace == unknown {
+		return "", false
+	}
+	return strings.TrimSuffix(iriTerm(namespace, "obo:"+o.Prefix+"_"), ">"), true
+}
+
+// subClassOf returns the qualified name of the ontology's subclass predicate.
+func (o Ontology) subClassOf() string {
+	if o.SubClassOf == "" {
+		return "rdfs:subClassOf"
+	}
+	return o.SubClassOf
+}
+
+// deprecated returns the qualified name of the ontology's deprecation
+// predicate.
+func (o Ontology) deprecated() string {
+	if o.Deprecated == "" {
+		return "owl:deprecated"
+	}
+	return o.Deprecated
+}
+
+// isTerm returns whether t is a term of the ontology in the given namespace
+// form.
+func (o Ontology) isTerm(namespace int, t rdf.Term) bool {
+	prefix, ok := o.termPrefix(namespace)
+	return ok && strings.HasPrefix(t.Value, prefix)
+}
@@ -0,0 +1,118 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+const hpOBO = `format-version: 1.4
+ontology: hp
+
+[Term]
+id: HP:0000001
+name: All
+
+[Term]
+id: HP:0000118
+name: Phenotypic abnormality
+is_a: HP:0000001
+
+[Term]
+id: HP:0000152
+name: Abnormality of head or neck
+is_a: HP:0000118
+
+[Term]
+id: HP:0000002
+name: obsolete Abnormality of body height
+is_a: HP:0000118
+is_obsolete: true
+`
+
+var hp = gogo.Ontology{Prefix: "HP", Roots: []string{"HP:0000001"}}
+
+func hpTerm(id string, local bool) string {
+	if local {
+		return "<obo:HP_" + id + ">"
+	}
+	return "<http://purl.obolibrary.org/obo/HP_" + id + ">"
+}
+
+func TestOntology(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, testOBO, local)
+		_, err := g.LoadOBO(strings.NewReader(hpOBO), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		root, ok := g.TermFor(hpTerm("0000001", local))
+		if !ok {
+			t.Fatal("missing HP root")
+		}
+
+		if desc := g.DescendantsOf(root); desc != nil {
+			t.Errorf("unexpected descendants of HP term in GO graph local=%t: %v", local, desc)
+		}
+
+		a := gogo.Ancestry{Graph: g, Ontology: &hp, ExcludeDeprecated: true}
+		got := termValues(descendantTerms(a.DescendantsOf(root)))
+		want := []string{hpTerm("0000118", local), hpTerm("0000152", local)}
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected HP descendants local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+
+		leaf, _ := g.TermFor(hpTerm("0000152", local))
+		if ok, depth := a.IsDescendantOf(root, leaf); !ok || depth != 2 {
+			t.Errorf("unexpected descendancy local=%t: got:%t %d want:true 2", local, ok, depth)
+		}
+
+		got = termValues(g.Roots(false))
+		want = []string{goTerm("0003674", local), goTerm("0005575", local), goTerm("0008150", local)}
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected GO roots local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+
+		g.SetOntology(hp)
+		if g.Ontology().Prefix != "HP" {
+			t.Errorf("unexpected ontology prefix: got:%s want:HP", g.Ontology().Prefix)
+		}
+		got = termValues(g.Roots(false))
+		want = []string{hpTerm("0000001", local)}
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected HP roots local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+		got = termValues(g.Roots(true))
+		if !equalStrings(got, want) {
+			t.Errorf("unexpected forced HP roots local=%t:\ngot: %v\nwant:%v", local, got, want)
+		}
+		if desc := g.DescendantsOf(root); len(desc) != 3 {
+			t.Errorf("unexpected number of descendants of HP root local=%t: got:%d want:3", local, len(desc))
+		}
+	}
+}
+
+func descendantTerms(desc []gogo.Descendant) []rdf.Term {
+	terms := make([]rdf.Term, len(desc))
+	for i, d := range desc {
+		terms[i] = d.Term
+	}
+	return terms
+}
+
+func termValues(terms []rdf.Term) []string {
+	var values []string
+	for _, t := range terms {
+		values = append(values, t.Value)
+	}
+	sort.Strings(values)
+	return values
+}
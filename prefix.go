@@ -0,0 +1,229 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// PrefixMap is a mapping from qualified name prefixes to their global IRI
+// namespaces.
+type PrefixMap map[string]string
+
+// DefaultPrefixes returns a new PrefixMap holding the built-in prefixes,
+// obo, oboInOwl, owl, rdf, rdfs and xsd. User prefixes may be added to
+// the returned map.
+func DefaultPrefixes() PrefixMap {
+	p := make(PrefixMap, len(prefixes))
+	for prefix, base := range prefixes {
+		p[prefix] = base
+	}
+	return p
+}
+
+// Form is the IRI form used by a Graph.
+type Form int
+
+const (
+	// UnknownForm is the form of a graph that has
+	// not yet had any statements added to it.
+	UnknownForm Form = unknown
+
+	// LocalForm is the form of a graph that uses
+	// qualified names, for example <obo:GO_0008150>.
+	LocalForm Form = local
+
+	// GlobalForm is the form of a graph that uses
+	// global IRIs, for example
+	// <http://purl.obolibrary.org/obo/GO_0008150>.
+	GlobalForm Form = global
+)
+
+func (f Form) String() string {
+	switch f {
+	case UnknownForm:
+		return "unknown"
+	case LocalForm:
+		return "local"
+	case GlobalForm:
+		return "global"
+	default:
+		return "invalid"
+	}
+}
+
+// Form returns the IRI form of the graph.
+func (g *Graph) Form() Form {
+	return Form(g.namespace)
+}
+
+// Normalize configures g to rewrite the IRIs of statements subsequently
+// added to the graph into the given form using the prefix map p. The
+// subject, predicate and object IRIs and literal datatype IRIs of added
+// statements are rewritten before the statement is checked, so statements
+// in either form may be added to a normalising graph. IRIs that cannot be
+// rewritten with the prefix map are left unaltered. The built-in prefixes
+// always take their built-in values. If form is UnknownForm, the form is
+// chosen by the first statement added to the graph. If p is nil,
+// normalisation is disabled.
+//
+// Normalize returns an error if g already holds statements in a form other
+// than form.
+func (g *Graph) Normalize(form Form, p PrefixMap) error {
+	switch form {
+	case UnknownForm, LocalForm, GlobalForm:
+	default:
+		return fmt.Errorf("gogo: invalid form: %d", form)
+	}
+	if form != UnknownForm && g.namespace != unknown && Form(g.namespace) != form {
+		return fmt.Errorf("gogo: cannot normalize %v form graph to %v form: %w", Form(g.namespace), form, ErrNamespace)
+	}
+	if p == nil {
+		g.prefixes = nil
+		return nil
+	}
+	g.prefixes = newPrefixMapper(p)
+	if form != UnknownForm {
+		g.namespace = int(form)
+	}
+	return nil
+}
+
+// Rewrite returns a new graph holding the statements of g with their IRIs
+// rewritten into the given form using the prefix map configured by Normalize,
+// or the built-in prefixes if g is not normalising. The returned graph
+// normalises statements added to it into the given form.
+func (g *Graph) Rewrite(form Form) (*Graph, error) {
+	if form != LocalForm && form != GlobalForm {
+		return nil, fmt.Errorf("gogo: invalid form: %d", form)
+	}
+	m := g.prefixes
+	if m == nil {
+		m = newPrefixMapper(nil)
+	}
+	dst := NewGraph()
+	dst.namespace = int(form)
+	dst.prefixes = m
+
+	it := g.AllStatements()
+	for it.Next() {
+		s := *it.Statement()
+		zeroUIDs(&s)
+		err := dst.InsertStatement(&s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// prefixMapper rewrites IRIs between local and global forms.
+type prefixMapper struct {
+	prefixes PrefixMap
+
+	// bases holds the prefixes ordered by
+	// descending length of their IRI, so
+	// that the longest match is found first.
+	bases []string
+}
+
+// newPrefixMapper returns a prefixMapper for the built-in prefixes and p.
+func newPrefixMapper(p PrefixMap) *prefixMapper {
+	m := &prefixMapper{prefixes: make(PrefixMap, len(p)+len(prefixes))}
+	for prefix, base := range p {
+		m.prefixes[prefix] = base
+	}
+	for prefix, base := range prefixes {
+		m.prefixes[prefix] = base
+	}
+	for prefix := range m.prefixes {
+		m.bases = append(m.bases, prefix)
+	}
+	sort.Slice(m.bases, func(i, j int) bool {
+		bi, bj := m.prefixes[m.bases[i]], m.prefixes[m.bases[j]]
+		if len(bi) != len(bj) {
+			return len(bi) > len(bj)
+		}
+		return m.bases[i] < m.bases[j]
+	})
+	return m
+}
+
+// iri returns iri rewritten into the given namespace form.
+func (m *prefixMapper) iri(namespace int, iri string) string {
+	switch namespace {
+	case global:
+		i := strings.Index(iri, ":")
+		if i < 0 {
+			return iri
+		}
+		base, ok := m.prefixes[iri[:i]]
+		if !ok {
+			return iri
+		}
+		return base + iri[i+1:]
+	case local:
+		for _, prefix := range m.bases {
+			base := m.prefixes[prefix]
+			if strings.HasPrefix(iri, base) {
+				return prefix + ":" + iri[len(base):]
+			}
+		}
+	}
+	return iri
+}
+
+// term returns t rewritten into the given namespace form. Blank nodes
+// and invalid terms are returned unaltered.
+func (m *prefixMapper) term(namespace int, t rdf.Term) rdf.Term {
+	text, qual, kind, err := t.Parts()
+	if err != nil {
+		return t
+	}
+	var n rdf.Term
+	switch kind {
+	case rdf.IRI:
+		n, err = rdf.NewIRITerm(m.iri(namespace, text))
+	case rdf.Literal:
+		if qual == "" || strings.HasPrefix(qual, "@") {
+			return t
+		}
+		n, err = rdf.NewLiteralTerm(text, m.iri(namespace, qual))
+	default:
+		return t
+	}
+	if err != nil {
+		return t
+	}
+	if n.Value != t.Value {
+		// The UID of an altered term cannot
+		// refer to the term's new value.
+		n.UID = 0
+	} else {
+		n.UID = t.UID
+	}
+	return n
+}
+
+// normalize returns a copy of s with its terms rewritten into the form of
+// g, or the form indicated by the predicate of s if g has no form.
+func (g *Graph) normalize(s *rdf.Statement) *rdf.Statement {
+	namespace := g.namespace
+	if namespace == unknown {
+		namespace = local
+		if strings.HasPrefix(s.Predicate.Value, "<http:") {
+			namespace = global
+		}
+	}
+	n := *s
+	n.Subject = g.prefixes.term(namespace, s.Subject)
+	n.Predicate = g.prefixes.term(namespace, s.Predicate)
+	n.Object = g.prefixes.term(namespace, s.Object)
+	return &n
+}
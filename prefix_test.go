@@ -0,0 +1,149 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var normalizeTests = []struct {
+	name string
+	form gogo.Form
+	in   string
+	want string
+}{
+	{
+		name: "global",
+		form: gogo.GlobalForm,
+		in: `<obo:GO_0000001> <rdfs:label> "thing"^^<xsd:string> .
+<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <ex:thing> .
+<ex:thing> <rdfs:comment> "comment"@en .
+_:b0 <rdf:type> <owl:Restriction> .
+`,
+		want: `<http://example.org/thing> <http://www.w3.org/2000/01/rdf-schema#comment> "comment"@en .
+<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2000/01/rdf-schema#label> "thing"^^<http://www.w3.org/2001/XMLSchema#string> .
+<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <http://example.org/thing> .
+_:b0 <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#Restriction> .
+`,
+	},
+	{
+		name: "local",
+		form: gogo.LocalForm,
+		in: `<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2000/01/rdf-schema#label> "thing"^^<http://www.w3.org/2001/XMLSchema#string> .
+<obo:GO_0000001> <rdfs:subClassOf> <http://example.org/thing> .
+<http://example.org/other> <rdfs:comment> "comment" .
+`,
+		want: `<ex:other> <rdfs:comment> "comment" .
+<obo:GO_0000001> <rdfs:label> "thing"^^<xsd:string> .
+<obo:GO_0000001> <rdfs:subClassOf> <ex:thing> .
+`,
+	},
+	{
+		name: "unknown",
+		form: gogo.UnknownForm,
+		in: `<obo:GO_0000001> <rdfs:label> "thing" .
+<http://purl.obolibrary.org/obo/GO_0000001> <http://www.w3.org/2000/01/rdf-schema#comment> "comment" .
+`,
+		want: `<obo:GO_0000001> <rdfs:comment> "comment" .
+<obo:GO_0000001> <rdfs:label> "thing" .
+`,
+	},
+}
+
+func TestNormalize(t *testing.T) {
+	prefixes := gogo.DefaultPrefixes()
+	prefixes["ex"] = "http://example.org/"
+	for _, test := range normalizeTests {
+		g := gogo.NewGraph()
+		err := g.Normalize(test.form, prefixes)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", test.name, err)
+		}
+		dec := rdf.NewDecoder(strings.NewReader(test.in))
+		for {
+			s, err := dec.Unmarshal()
+			if err != nil {
+				break
+			}
+			s.Subject.UID, s.Predicate.UID, s.Object.UID = 0, 0, 0
+			err = g.InsertStatement(s)
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", test.name, err)
+			}
+		}
+		var buf bytes.Buffer
+		err = g.Encode(&buf, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", test.name, err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("unexpected normalised statements for %q:\ngot:\n%s\nwant:\n%s", test.name, &buf, test.want)
+		}
+	}
+}
+
+func TestNormalizeFormMismatch(t *testing.T) {
+	g, _, err := graphFromReader(strings.NewReader(`<obo:GO_0000001> <rdfs:label> "thing" .`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = g.Normalize(gogo.GlobalForm, gogo.DefaultPrefixes())
+	if !errors.Is(err, gogo.ErrNamespace) {
+		t.Errorf("unexpected error: got:%v want:%v", err, gogo.ErrNamespace)
+	}
+	const want = "gogo: cannot normalize local form graph to global form: predicate namespace does not match graph"
+	if err == nil || err.Error() != want {
+		t.Errorf("unexpected error message: got:%v want:%s", err, want)
+	}
+	err = g.Normalize(gogo.LocalForm, gogo.DefaultPrefixes())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	g := oboGraph(t, testOBO, false)
+	var want bytes.Buffer
+	err := g.Encode(&want, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	l, err := g.Rewrite(gogo.LocalForm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Form() != gogo.LocalForm {
+		t.Errorf("unexpected form: got:%v want:%v", l.Form(), gogo.LocalForm)
+	}
+	term, ok := l.TermFor("<obo:GO_0007154>")
+	if !ok {
+		t.Fatal("missing locally namespaced cell communication term")
+	}
+	desc := gogo.Ancestry{Graph: l, Relations: gogo.IsAPartOf}.DescendantsOf(term)
+	if len(desc) != 1 || desc[0].Term.Value != "<obo:GO_0007165>" {
+		t.Errorf("unexpected descendants of rewritten term: %v", desc)
+	}
+
+	r, err := l.Rewrite(gogo.GlobalForm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got bytes.Buffer
+	err = r.Encode(&got, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("round trip rewrite does not match original:\ngot:\n%s\nwant:\n%s", &got, &want)
+	}
+}
@@ -150,6 +150,17 @@ func min(a, b int) int {
 	return b
 }
 
+// Filter returns a query holding the terms of q that satisfy fn.
+func (q Query) Filter(fn func(t rdf.Term) bool) Query {
+	r := Query{g: q.g}
+	for _, t := range q.terms {
+		if fn(t) {
+			r.terms = append(r.terms, t)
+		}
+	}
+	return r
+}
+
 // Unique returns a copy of the receiver that contains only one instance
 // of each term.
 func (q Query) Unique() Query {
@@ -0,0 +1,139 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// IDKind describes how an identifier was resolved to a term.
+type IDKind int
+
+const (
+	// UnknownID indicates that the identifier
+	// did not resolve to a term.
+	UnknownID IDKind = iota
+
+	// PrimaryID indicates that the identifier
+	// is the primary identifier of a term.
+	PrimaryID
+
+	// AlternativeID indicates that the
+	// identifier is an alternative identifier
+	// of a term.
+	AlternativeID
+
+	// ObsoleteID indicates that the identifier
+	// is the primary identifier of an obsolete
+	// term.
+	ObsoleteID
+)
+
+func (k IDKind) String() string {
+	switch k {
+	case UnknownID:
+		return "unknown"
+	case PrimaryID:
+		return "primary"
+	case AlternativeID:
+		return "alternative"
+	case ObsoleteID:
+		return "obsolete"
+	default:
+		return "invalid"
+	}
+}
+
+// Resolve returns the term identified by id and how id was resolved. The
+// identifier may be an OBO CURIE such as "GO:0008150", an underscore form
+// such as "GO_0008150", a qualified name with a known prefix such as
+// "obo:GO_0008150", or an IRI with or without enclosing angle brackets, and
+// is resolved in the namespace form of g. Known prefixes are the built-in
+// prefixes and, if g is normalising, the prefixes registered with
+// Normalize. If id is not the primary identifier of a term, terms with a
+// matching oboInOwl:hasAlternativeId are searched. Obsolete terms are
+// returned with the ObsoleteID kind; their replacements can be found with
+// the Replacement and Consider methods. If more than one term has id as an
+// alternative identifier, the term with the lexically first value is
+// returned.
+func (g *Graph) Resolve(id string) (t rdf.Term, kind IDKind, ok bool) {
+	m := g.prefixMap()
+	iri := m.globalIRI(strings.TrimSpace(id))
+	if iri == "" {
+		return t, UnknownID, false
+	}
+
+	namespace := g.namespace
+	if namespace == unknown {
+		namespace = global
+	}
+	t, ok = g.TermFor("<" + m.iri(namespace, iri) + ">")
+	if ok && g.Node(t.UID) != nil {
+		if g.IsObsolete(t) {
+			return t, ObsoleteID, true
+		}
+		return t, PrimaryID, true
+	}
+
+	curie := oboID(iri)
+	var primary []rdf.Term
+	for _, typ := range []string{"", "xsd:string"} {
+		lit, err := rdf.NewLiteralTerm(curie, expand(g.namespace, typ))
+		if err != nil {
+			continue
+		}
+		lit, ok = g.TermFor(lit.Value)
+		if !ok {
+			continue
+		}
+		primary = append(primary, g.subjects(lit, "oboInOwl:hasAlternativeId")...)
+	}
+	if len(primary) == 0 {
+		return rdf.Term{}, UnknownID, false
+	}
+	sortTerms(primary)
+	return primary[0], AlternativeID, true
+}
+
+// builtinPrefixes is the prefix mapper for the built-in prefixes.
+var builtinPrefixes = newPrefixMapper(nil)
+
+// prefixMap returns the prefix mapper used by g, or the mapper for the
+// built-in prefixes if g is not normalising.
+func (g *Graph) prefixMap() *prefixMapper {
+	if g.prefixes != nil {
+		return g.prefixes
+	}
+	return builtinPrefixes
+}
+
+// globalIRI returns the global IRI, without enclosing angle brackets, for
+// the identifier id. Identifiers with an unknown prefix are treated as OBO
+// CURIEs. It returns the empty string if id is not a valid identifier.
+func (m *prefixMapper) globalIRI(id string) string {
+	if strings.HasPrefix(id, "<") && strings.HasSuffix(id, ">") {
+		id = id[1 : len(id)-1]
+	}
+	if id == "" || strings.ContainsAny(id, " <>\"") {
+		return ""
+	}
+	if strings.Contains(id, "://") {
+		return id
+	}
+	i := strings.Index(id, ":")
+	if i < 0 {
+		i = strings.Index(id, "_")
+		if i <= 0 {
+			return ""
+		}
+		return m.iri(global, "obo:"+id)
+	}
+	if _, ok := m.prefixes[id[:i]]; ok {
+		return m.iri(global, id)
+	}
+	return m.iri(global, oboQName(id, ""))
+}
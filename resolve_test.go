@@ -0,0 +1,92 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var resolveTests = []struct {
+	id       string
+	want     string
+	wantKind gogo.IDKind
+}{
+	{id: "GO:0008150", want: "0008150", wantKind: gogo.PrimaryID},
+	{id: "GO_0008150", want: "0008150", wantKind: gogo.PrimaryID},
+	{id: "obo:GO_0008150", want: "0008150", wantKind: gogo.PrimaryID},
+	{id: "http://purl.obolibrary.org/obo/GO_0008150", want: "0008150", wantKind: gogo.PrimaryID},
+	{id: "<http://purl.obolibrary.org/obo/GO_0008150>", want: "0008150", wantKind: gogo.PrimaryID},
+	{id: " GO:0007165 ", want: "0007165", wantKind: gogo.PrimaryID},
+	{id: "GO:0000004", want: "0008150", wantKind: gogo.AlternativeID},
+	{id: "<http://purl.obolibrary.org/obo/GO_0000004>", want: "0008150", wantKind: gogo.AlternativeID},
+	{id: "GO:0000001", want: "0000001", wantKind: gogo.ObsoleteID},
+	{id: "GO:9999999", wantKind: gogo.UnknownID},
+	{id: "rdfs:label", wantKind: gogo.UnknownID},
+	{id: "", wantKind: gogo.UnknownID},
+	{id: "GO 0008150", wantKind: gogo.UnknownID},
+}
+
+func TestResolve(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, testOBO, local)
+		for _, test := range resolveTests {
+			got, kind, ok := g.Resolve(test.id)
+			if ok != (test.wantKind != gogo.UnknownID) || kind != test.wantKind {
+				t.Errorf("unexpected resolution kind for %q local=%t: got:%v %t want:%v",
+					test.id, local, kind, ok, test.wantKind)
+				continue
+			}
+			if ok && got.Value != goTerm(test.want, local) {
+				t.Errorf("unexpected term for %q local=%t: got:%s want:%s",
+					test.id, local, got.Value, goTerm(test.want, local))
+			}
+		}
+	}
+}
+
+func TestResolvePrefixMap(t *testing.T) {
+	prefixes := gogo.DefaultPrefixes()
+	prefixes["ex"] = "http://example.org/"
+	for _, form := range []gogo.Form{gogo.GlobalForm, gogo.LocalForm} {
+		g := gogo.NewGraph()
+		err := g.Normalize(form, prefixes)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = g.LoadOBO(strings.NewReader(testOBO), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = g.InsertStatement(&rdf.Statement{
+			Subject:   rdf.Term{Value: "<http://example.org/thing>"},
+			Predicate: rdf.Term{Value: "<http://www.w3.org/2000/01/rdf-schema#subClassOf>"},
+			Object:    rdf.Term{Value: "<http://purl.obolibrary.org/obo/GO_0008150>"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := "<http://example.org/thing>"
+		if form == gogo.LocalForm {
+			want = "<ex:thing>"
+		}
+		for _, id := range []string{"ex:thing", "http://example.org/thing", "<http://example.org/thing>"} {
+			got, kind, ok := g.Resolve(id)
+			if !ok || kind != gogo.PrimaryID || got.Value != want {
+				t.Errorf("unexpected resolution of %q in %v form: got:%s %v %t want:%s %v true",
+					id, form, got.Value, kind, ok, want, gogo.PrimaryID)
+			}
+		}
+		got, _, ok := g.Resolve("GO:0007165")
+		if !ok || got.Value != goTerm("0007165", form == gogo.LocalForm) {
+			t.Errorf("unexpected resolution of GO:0007165 in %v form: got:%s %t", form, got.Value, ok)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TermMeasure is a pairwise term similarity measure such as
+// Similarity.Resnik.
+type TermMeasure func(x, y rdf.Term) float64
+
+// SetMeasure is a term set similarity measure.
+type SetMeasure func(a, b []rdf.Term) float64
+
+// BestMatchAverage returns a set measure that is the best-match average of
+// the term measure m: the mean over all terms in both sets of the best
+// similarity of each term to a term in the other set.
+func BestMatchAverage(m TermMeasure) SetMeasure {
+	return func(a, b []rdf.Term) float64 {
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		rows, cols := bestMatches(a, b, m)
+		return (sum(rows) + sum(cols)) / float64(len(a)+len(b))
+	}
+}
+
+// MaxMeasure returns a set measure that is the maximum of the term measure
+// m over all pairs of terms from the two sets.
+func MaxMeasure(m TermMeasure) SetMeasure {
+	return func(a, b []rdf.Term) float64 {
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		rows, _ := bestMatches(a, b, m)
+		max := math.Inf(-1)
+		for _, v := range rows {
+			max = math.Max(max, v)
+		}
+		return max
+	}
+}
+
+// AverageMeasure returns a set measure that is the mean of the term measure
+// m over all pairs of terms from the two sets.
+func AverageMeasure(m TermMeasure) SetMeasure {
+	return func(a, b []rdf.Term) float64 {
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		var total float64
+		for _, x := range a {
+			for _, y := range b {
+				total += m(x, y)
+			}
+		}
+		return total / float64(len(a)*len(b))
+	}
+}
+
+// FunSimMax returns a set measure that is the funSimMax measure of Schlicker
+// et al. (2006) for the term measure m: the maximum of the mean best match
+// scores of the terms in each set. Schlicker et al. use Similarity.Relevance
+// as the term measure.
+func FunSimMax(m TermMeasure) SetMeasure {
+	return func(a, b []rdf.Term) float64 {
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		rows, cols := bestMatches(a, b, m)
+		return math.Max(sum(rows)/float64(len(a)), sum(cols)/float64(len(b)))
+	}
+}
+
+// FunSimAvg returns a set measure that is the funSimAvg measure of Schlicker
+// et al. (2006) for the term measure m: the mean of the mean best match
+// scores of the terms in each set.
+func FunSimAvg(m TermMeasure) SetMeasure {
+	return func(a, b []rdf.Term) float64 {
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		rows, cols := bestMatches(a, b, m)
+		return (sum(rows)/float64(len(a)) + sum(cols)/float64(len(b))) / 2
+	}
+}
+
+// bestMatches returns the best score of each term in a against the terms
+// in b, and of each term in b against the terms in a.
+func bestMatches(a, b []rdf.Term, m TermMeasure) (rows, cols []float64) {
+	rows = make([]float64, len(a))
+	cols = make([]float64, len(b))
+	for i := range rows {
+		rows[i] = math.Inf(-1)
+	}
+	for j := range cols {
+		cols[j] = math.Inf(-1)
+	}
+	for i, x := range a {
+		for j, y := range b {
+			v := m(x, y)
+			rows[i] = math.Max(rows[i], v)
+			cols[j] = math.Max(cols[j], v)
+		}
+	}
+	return rows, cols
+}
+
+func sum(s []float64) float64 {
+	var t float64
+	for _, v := range s {
+		t += v
+	}
+	return t
+}
+
+// SimGIC returns the graph information content similarity of the term sets
+// a and b, the sum of the information content of the terms in the
+// intersection of the ancestor closures of a and b divided by the sum of
+// the information content of the terms in their union. SimGIC is a
+// SetMeasure.
+func (s Similarity) SimGIC(a, b []rdf.Term) float64 {
+	ca := s.closure(a)
+	cb := s.closure(b)
+	var inter, union float64
+	for uid, t := range ca {
+		v := s.IC.Score(t)
+		union += v
+		if _, ok := cb[uid]; ok {
+			inter += v
+		}
+	}
+	for uid, t := range cb {
+		if _, ok := ca[uid]; !ok {
+			union += s.IC.Score(t)
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return inter / union
+}
+
+// SimUI returns the union-intersection similarity of the term sets a and
+// b, the number of terms in the intersection of the ancestor closures of
+// a and b divided by the number of terms in their union. SimUI is a
+// SetMeasure.
+func (s Similarity) SimUI(a, b []rdf.Term) float64 {
+	ca := s.closure(a)
+	cb := s.closure(b)
+	var inter int
+	for uid := range ca {
+		if _, ok := cb[uid]; ok {
+			inter++
+		}
+	}
+	union := len(ca) + len(cb) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+// closure returns the terms in set and all their ancestors keyed by UID.
+func (s Similarity) closure(set []rdf.Term) map[int64]rdf.Term {
+	c := make(map[int64]rdf.Term)
+	for _, t := range set {
+		if !s.Ancestry.isTerm(t) {
+			continue
+		}
+		for uid, a := range s.Ancestry.terms(t) {
+			c[uid] = a
+		}
+	}
+	return c
+}
+
+// AspectTermsFor returns the terms directly and positively annotated to the
+// gene product with the given identifier that have the GO aspect a, sorted
+// by value. If a is UnknownAspect, all the annotated terms are returned.
+func (s *Annotations) AspectTermsFor(gene string, a Aspect) []rdf.Term {
+	terms := s.TermsFor(gene)
+	if a == UnknownAspect {
+		return terms
+	}
+	n := 0
+	for _, t := range terms {
+		if s.ancestry.Graph.Aspect(t) == a {
+			terms[n] = t
+			n++
+		}
+	}
+	return terms[:n]
+}
+
+// GeneSimilarity returns the similarity of the gene products x and y
+// according to the set measure m applied to their direct positive
+// annotations with the GO aspect a. If a is UnknownAspect, all annotations
+// are used.
+func (s *Annotations) GeneSimilarity(x, y string, m SetMeasure, a Aspect) float64 {
+	return m(s.AspectTermsFor(x, a), s.AspectTermsFor(y, a))
+}
+
+// SimilarityMatrix returns the matrix of similarities between the gene
+// products in genes according to the set measure m applied to their direct
+// positive annotations with the GO aspect a. Element i, j of the returned
+// matrix is the similarity of genes[i] and genes[j]. The set measure must
+// be symmetric. If genes is empty, SimilarityMatrix returns nil.
+func (s *Annotations) SimilarityMatrix(genes []string, m SetMeasure, a Aspect) *mat.SymDense {
+	if len(genes) == 0 {
+		return nil
+	}
+	terms := make([][]rdf.Term, len(genes))
+	for i, g := range genes {
+		terms[i] = s.AspectTermsFor(g, a)
+	}
+	sim := mat.NewSymDense(len(genes), nil)
+	for i := range genes {
+		for j := i; j < len(genes); j++ {
+			sim.SetSym(i, j, m(terms[i], terms[j]))
+		}
+	}
+	return sim
+}
@@ -0,0 +1,96 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/graph/formats/rdf"
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/kortschak/gogo"
+)
+
+func TestSetSimilarity(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		anc := gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf}
+		s := gogo.Similarity{Ancestry: anc, IC: gogo.NewAnnotationIC(annotationStore(t, anc))}
+
+		terms := func(ids ...string) []rdf.Term {
+			var set []rdf.Term
+			for _, id := range ids {
+				term, ok := g.TermFor(goTerm(id, local))
+				if !ok {
+					t.Fatalf("missing term for %q", id)
+				}
+				set = append(set, term)
+			}
+			return set
+		}
+		a := terms("0007165", "0010646")
+		b := terms("0007154")
+
+		for _, test := range []struct {
+			name string
+			m    gogo.SetMeasure
+			want float64
+		}{
+			{name: "BMA", m: gogo.BestMatchAverage(s.Path), want: 7.0 / 18},
+			{name: "max", m: gogo.MaxMeasure(s.Path), want: 0.5},
+			{name: "average", m: gogo.AverageMeasure(s.Path), want: 1.0 / 3},
+			{name: "funSimMax", m: gogo.FunSimMax(s.Path), want: 0.5},
+			{name: "funSimAvg", m: gogo.FunSimAvg(s.Path), want: 5.0 / 12},
+			{name: "SimUI", m: s.SimUI, want: 3.0 / 7},
+			{name: "SimGIC", m: s.SimGIC, want: (math.Log(1.5) + math.Log(3)) / (math.Log(1.5) + 5*math.Log(3))},
+		} {
+			got := test.m(a, b)
+			if !scalar.EqualWithinAbsOrRel(got, test.want, 1e-12, 1e-12) {
+				t.Errorf("unexpected %s similarity local=%t: got:%v want:%v", test.name, local, got, test.want)
+			}
+			rev := test.m(b, a)
+			if !scalar.EqualWithinAbsOrRel(rev, got, 1e-12, 1e-12) {
+				t.Errorf("asymmetric %s similarity local=%t: %v != %v", test.name, local, got, rev)
+			}
+			if got := test.m(a, nil); got != 0 {
+				t.Errorf("unexpected %s similarity with empty set local=%t: got:%v", test.name, local, got)
+			}
+		}
+	}
+}
+
+func TestSimilarityMatrix(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		anc := gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf}
+		store := annotationStore(t, anc)
+		s := gogo.Similarity{Ancestry: anc}
+
+		genes := []string{"g1", "g2", "g3"}
+		got := store.SimilarityMatrix(genes, s.SimUI, gogo.UnknownAspect)
+		want := mat.NewSymDense(3, []float64{
+			1, 1, 1.0 / 7,
+			1, 1, 1.0 / 7,
+			1.0 / 7, 1.0 / 7, 1,
+		})
+		if !mat.EqualApprox(got, want, 1e-12) {
+			t.Errorf("unexpected similarity matrix local=%t:\ngot: %v\nwant:%v", local, mat.Formatted(got), mat.Formatted(want))
+		}
+		if sim := store.GeneSimilarity("g1", "g3", s.SimUI, gogo.BiologicalProcess); sim != 1.0/7 {
+			t.Errorf("unexpected gene similarity local=%t: got:%v want:%v", local, sim, 1.0/7)
+		}
+
+		got = store.SimilarityMatrix(genes, s.SimUI, gogo.MolecularFunction)
+		if !mat.Equal(got, mat.NewSymDense(3, nil)) {
+			t.Errorf("unexpected molecular function similarity matrix local=%t:\n%v", local, mat.Formatted(got))
+		}
+
+		if store.SimilarityMatrix(nil, s.SimUI, gogo.UnknownAspect) != nil {
+			t.Errorf("unexpected non-nil similarity matrix for empty gene list")
+		}
+	}
+}
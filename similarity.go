@@ -0,0 +1,256 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// MostInformativeCommonAncestor returns the common ancestor of x and y with
+// the greatest information content in ic, and its information content. Terms
+// are considered to be their own ancestors. Ties are broken by term value.
+// If x and y have no common ancestor in ic, ok is false.
+func (a Ancestry) MostInformativeCommonAncestor(x, y rdf.Term, ic *InformationContent) (mica rdf.Term, v float64, ok bool) {
+	if !a.isTerm(x) || !a.isTerm(y) {
+		return mica, 0, false
+	}
+	dx := a.terms(x)
+	dy := a.terms(y)
+	for uid, t := range dx {
+		if _, common := dy[uid]; !common {
+			continue
+		}
+		c, in := ic.Of(t)
+		if !in {
+			continue
+		}
+		if !ok || c > v || (c == v && t.Value < mica.Value) {
+			mica, v, ok = t, c, true
+		}
+	}
+	return mica, v, ok
+}
+
+// terms returns t and its ancestors keyed by UID.
+func (a Ancestry) terms(t rdf.Term) map[int64]rdf.Term {
+	terms := make(map[int64]rdf.Term)
+	walk(t, a.parents, func(n rdf.Term, _ int) bool {
+		terms[n.UID] = n
+		return false
+	})
+	return terms
+}
+
+// Similarity provides semantic similarity measures between ontology terms.
+type Similarity struct {
+	// Ancestry specifies the graph and the
+	// relations used to find ancestors.
+	Ancestry Ancestry
+
+	// IC is the information content model
+	// used by information content based
+	// measures.
+	IC *InformationContent
+
+	// WangWeights holds the semantic
+	// contribution factor of each relation
+	// for the Wang measure. If WangWeights
+	// is nil, is_a has a weight of 0.8 and
+	// part_of has a weight of 0.6. Relations
+	// without a weight do not contribute.
+	WangWeights map[Relation]float64
+}
+
+// Resnik returns the Resnik similarity of x and y, the information content
+// of their most informative common ancestor.
+func (s Similarity) Resnik(x, y rdf.Term) float64 {
+	_, v, _ := s.Ancestry.MostInformativeCommonAncestor(x, y, s.IC)
+	return v
+}
+
+// Lin returns the Lin similarity of x and y,
+//
+//	2*IC(mica) / (IC(x) + IC(y)).
+//
+// If x and y both have zero information content, Lin returns 1 if x and y
+// are the same term and 0 otherwise.
+func (s Similarity) Lin(x, y rdf.Term) float64 {
+	_, mica, ok := s.Ancestry.MostInformativeCommonAncestor(x, y, s.IC)
+	if !ok {
+		return 0
+	}
+	icx, okx := s.IC.Of(x)
+	icy, oky := s.IC.Of(y)
+	if !okx || !oky {
+		return 0
+	}
+	if icx+icy == 0 {
+		if x == y {
+			return 1
+		}
+		return 0
+	}
+	return 2 * mica / (icx + icy)
+}
+
+// JiangConrath returns the Jiang-Conrath similarity of x and y,
+//
+//	1 / (1 + IC(x) + IC(y) - 2*IC(mica)).
+func (s Similarity) JiangConrath(x, y rdf.Term) float64 {
+	_, mica, ok := s.Ancestry.MostInformativeCommonAncestor(x, y, s.IC)
+	if !ok {
+		return 0
+	}
+	icx, okx := s.IC.Of(x)
+	icy, oky := s.IC.Of(y)
+	if !okx || !oky {
+		return 0
+	}
+	return 1 / (1 + icx + icy - 2*mica)
+}
+
+// Relevance returns the relevance similarity of Schlicker et al. (2006) of
+// x and y,
+//
+//	Lin(x, y) * (1 - p(mica))
+//
+// where p(mica) = exp(-IC(mica)) is the probability of the most informative
+// common ancestor. The measure is only meaningful for annotation based
+// information content.
+func (s Similarity) Relevance(x, y rdf.Term) float64 {
+	_, mica, ok := s.Ancestry.MostInformativeCommonAncestor(x, y, s.IC)
+	if !ok {
+		return 0
+	}
+	return s.Lin(x, y) * (1 - math.Exp(-mica))
+}
+
+// Wang returns the graph-based similarity of Wang et al. (2007) of x and y.
+// The semantic value of each ancestor t of a term in the term's ancestor
+// graph is the maximum over paths from the term to t of the product of the
+// relation weights along the path, and the similarity is the sum of the
+// semantic values of the common ancestors of x and y for both terms divided
+// by the sum of all the semantic values for both terms.
+func (s Similarity) Wang(x, y rdf.Term) float64 {
+	if !s.Ancestry.isTerm(x) || !s.Ancestry.isTerm(y) {
+		return 0
+	}
+	sx := s.semanticValues(x)
+	sy := s.semanticValues(y)
+	var common, total float64
+	for uid, v := range sx {
+		total += v
+		if w, ok := sy[uid]; ok {
+			common += v + w
+		}
+	}
+	for _, v := range sy {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	return common / total
+}
+
+// semanticValues returns the Wang semantic values of t and its ancestors
+// keyed by UID.
+func (s Similarity) semanticValues(t rdf.Term) map[int64]float64 {
+	weights := s.WangWeights
+	if weights == nil {
+		weights = map[Relation]float64{IsA: 0.8, PartOf: 0.6}
+	}
+
+	// The semantic values are the maximum products
+	// of weights no greater than one, so terms can
+	// be finalised in order of decreasing value.
+	values := map[int64]float64{t.UID: 1}
+	terms := map[int64]rdf.Term{t.UID: t}
+	done := make(map[int64]bool)
+	for {
+		var (
+			best  int64
+			found bool
+		)
+		for uid, v := range values {
+			if !done[uid] && (!found || v > values[best] || (v == values[best] && uid < best)) {
+				best, found = uid, true
+			}
+		}
+		if !found {
+			return values
+		}
+		done[best] = true
+		for _, l := range s.Ancestry.parents(terms[best]) {
+			w, ok := weights[l.rel]
+			if !ok || w <= 0 {
+				continue
+			}
+			v := values[best] * w
+			if v > values[l.term.UID] {
+				values[l.term.UID] = v
+				terms[l.term.UID] = l.term
+			}
+		}
+	}
+}
+
+// Path returns the path similarity of x and y,
+//
+//	1 / (1 + d)
+//
+// where d is the length of the shortest path between x and y through a
+// common ancestor.
+func (s Similarity) Path(x, y rdf.Term) float64 {
+	if !s.Ancestry.isTerm(x) || !s.Ancestry.isTerm(y) {
+		return 0
+	}
+	dx := s.Ancestry.distances(x)
+	dy := s.Ancestry.distances(y)
+	d := -1
+	for uid, n := range dx {
+		if m, ok := dy[uid]; ok && (d < 0 || n+m < d) {
+			d = n + m
+		}
+	}
+	if d < 0 {
+		return 0
+	}
+	return 1 / (1 + float64(d))
+}
+
+// WuPalmer returns the depth-based similarity of Wu and Palmer (1994) of x
+// and y, the maximum over common ancestors c of
+//
+//	2*D(c) / (d(x, c) + d(y, c) + 2*D(c))
+//
+// where D(c) is one more than the Depth of c and d is the shortest path
+// length. If x or y is not a term in the graph, WuPalmer returns zero.
+func (s Similarity) WuPalmer(x, y rdf.Term) float64 {
+	if !s.Ancestry.inGraph(x) || !s.Ancestry.inGraph(y) {
+		return 0
+	}
+	dx := s.Ancestry.distances(x)
+	dy := s.Ancestry.distances(y)
+	var sim float64
+	for uid, n := range dx {
+		m, ok := dy[uid]
+		if !ok {
+			continue
+		}
+		c, ok := s.Ancestry.Graph.Node(uid).(rdf.Term)
+		if !ok {
+			continue
+		}
+		depth := float64(s.Ancestry.Depth(c) + 1)
+		v := 2 * depth / (float64(n+m) + 2*depth)
+		if v > sim {
+			sim = v
+		}
+	}
+	return sim
+}
@@ -0,0 +1,128 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var similarityTests = []struct {
+	x, y string
+
+	mica string
+
+	resnik, lin, jc, relevance float64
+	wang, path, wuPalmer       float64
+}{
+	{
+		x: "0007165", y: "0007154",
+		mica:   "0007154",
+		resnik: math.Log(3), lin: 1, jc: 1, relevance: 2.0 / 3,
+		wang: 4.48 / 5.48, path: 0.5, wuPalmer: 6.0 / 7,
+	},
+	{
+		x: "0007165", y: "0010646",
+		mica:   "0008150",
+		resnik: 0, lin: 0, jc: 1 / (1 + 2*math.Log(3)), relevance: 0,
+		// S_x = {0007165:1, 0009987:0.8, 0007154:0.6, 0008150:0.64}
+		// S_y = {0010646:1, 0050789:0.8, 0065007:0.64, 0008150:0.512}
+		wang: (0.64 + 0.512) / (3.04 + 2.952), path: 1.0 / 6, wuPalmer: 2.0 / 7,
+	},
+	{
+		x: "0007165", y: "0007165",
+		// 0007154 and 0007165 have the same IC,
+		// so the tie is broken by term value.
+		mica:   "0007154",
+		resnik: math.Log(3), lin: 1, jc: 1, relevance: 2.0 / 3,
+		wang: 1, path: 1, wuPalmer: 1,
+	},
+}
+
+func TestSimilarity(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		a := gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf}
+		s := gogo.Similarity{Ancestry: a, IC: gogo.NewAnnotationIC(annotationStore(t, a))}
+
+		for _, test := range similarityTests {
+			x, _ := g.TermFor(goTerm(test.x, local))
+			y, _ := g.TermFor(goTerm(test.y, local))
+
+			mica, _, ok := a.MostInformativeCommonAncestor(x, y, s.IC)
+			if !ok || mica.Value != goTerm(test.mica, local) {
+				t.Errorf("unexpected MICA for %s %s local=%t: got:%s want:%s", test.x, test.y, local, mica.Value, goTerm(test.mica, local))
+			}
+			for _, m := range []struct {
+				name string
+				fn   func(x, y rdf.Term) float64
+				want float64
+			}{
+				{name: "Resnik", fn: s.Resnik, want: test.resnik},
+				{name: "Lin", fn: s.Lin, want: test.lin},
+				{name: "Jiang-Conrath", fn: s.JiangConrath, want: test.jc},
+				{name: "Relevance", fn: s.Relevance, want: test.relevance},
+				{name: "Wang", fn: s.Wang, want: test.wang},
+				{name: "Path", fn: s.Path, want: test.path},
+				{name: "Wu-Palmer", fn: s.WuPalmer, want: test.wuPalmer},
+			} {
+				got := m.fn(x, y)
+				if !scalar.EqualWithinAbsOrRel(got, m.want, 1e-12, 1e-12) {
+					t.Errorf("unexpected %s similarity for %s %s local=%t: got:%v want:%v", m.name, test.x, test.y, local, got, m.want)
+				}
+				if rev := m.fn(y, x); !scalar.EqualWithinAbsOrRel(rev, got, 1e-12, 1e-12) {
+					t.Errorf("asymmetric %s similarity for %s %s local=%t: %v != %v", m.name, test.x, test.y, local, got, rev)
+				}
+			}
+		}
+	}
+}
+
+func TestWangWeights(t *testing.T) {
+	g := oboGraph(t, ancestryOBO, false)
+	x, _ := g.TermFor(goTerm("0007165", false))
+	y, _ := g.TermFor(goTerm("0007154", false))
+	s := gogo.Similarity{
+		Ancestry:    gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf},
+		WangWeights: map[gogo.Relation]float64{gogo.IsA: 0.5},
+	}
+	// Without a part_of weight the ancestor graph of x is
+	// {0007165:1, 0009987:0.5, 0008150:0.25} and of y is
+	// {0007154:1, 0009987:0.5, 0008150:0.25}.
+	want := 1.5 / 3.5
+	if got := s.Wang(x, y); !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+		t.Errorf("unexpected Wang similarity: got:%v want:%v", got, want)
+	}
+}
+
+func TestWuPalmerNotInGraph(t *testing.T) {
+	g, _, err := graphFromReader(strings.NewReader(`<obo:GO_1> <rdfs:subClassOf> <obo:GO_2> .`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	known, ok := g.TermFor("<obo:GO_1>")
+	if !ok {
+		t.Fatal("missing term for GO_1")
+	}
+	s := gogo.Similarity{Ancestry: gogo.Ancestry{Graph: g}}
+	for _, test := range []struct {
+		name string
+		x, y rdf.Term
+	}{
+		{name: "zero UIDs", x: rdf.Term{Value: "<obo:GO_9>"}, y: rdf.Term{Value: "<obo:GO_8>"}},
+		{name: "known and zero UID", x: known, y: rdf.Term{Value: "<obo:GO_8>"}},
+		{name: "absent UIDs", x: rdf.Term{Value: "<obo:GO_9>", UID: 1000}, y: rdf.Term{Value: "<obo:GO_8>", UID: 1001}},
+	} {
+		if got := s.WuPalmer(test.x, test.y); got != 0 {
+			t.Errorf("unexpected Wu-Palmer similarity for %s: got:%v want:0", test.name, got)
+		}
+	}
+}
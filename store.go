@@ -0,0 +1,230 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// Annotations is an index of gene product annotations to the terms of a
+// Graph. Annotations propagate according to the true path rule: a gene
+// product annotated to a term is also annotated to all the ancestors of
+// the term, and a gene product annotated as NOT having a relation to a
+// term does not have that relation to any descendant of the term.
+type Annotations struct {
+	// ancestry is the graph and relations used
+	// for annotation propagation.
+	ancestry Ancestry
+
+	annotations []*Annotation
+	byGene      map[string][]*Annotation
+	byTerm      map[int64][]*Annotation
+}
+
+// NewAnnotations returns a new empty annotation index for the terms of
+// a.Graph. Annotations are propagated through the relations followed by a.
+func NewAnnotations(a Ancestry) *Annotations {
+	return &Annotations{
+		ancestry: a,
+		byGene:   make(map[string][]*Annotation),
+		byTerm:   make(map[int64][]*Annotation),
+	}
+}
+
+// Add adds the annotation to the index. If the annotation's term has not
+// been resolved, it is resolved against the index's graph. Add returns an
+// error if the annotation has no gene product identifier or its term is
+// not in the graph.
+func (s *Annotations) Add(a *Annotation) error {
+	if a.GeneProduct.ID == "" {
+		return errors.New("gogo: annotation has no gene product identifier")
+	}
+	if a.TermKind == UnknownID {
+		a.resolveTerm(s.ancestry.Graph)
+		if a.TermKind == UnknownID {
+			return fmt.Errorf("gogo: annotation term not in graph: %s", a.TermID)
+		}
+	}
+	s.add(a)
+	return nil
+}
+
+// add adds the resolved annotation to the index.
+func (s *Annotations) add(a *Annotation) {
+	s.annotations = append(s.annotations, a)
+	s.byGene[a.GeneProduct.ID] = append(s.byGene[a.GeneProduct.ID], a)
+	s.byTerm[a.Term.UID] = append(s.byTerm[a.Term.UID], a)
+}
+
+// Filter returns a new annotation index holding the annotations of s that
+// are accepted by fn. The returned index propagates annotations in the same
+// way as s.
+func (s *Annotations) Filter(fn func(*Annotation) bool) *Annotations {
+	f := NewAnnotations(s.ancestry)
+	for _, a := range s.annotations {
+		if fn(a) {
+			f.add(a)
+		}
+	}
+	return f
+}
+
+// Len returns the number of annotations in the index.
+func (s *Annotations) Len() int {
+	return len(s.annotations)
+}
+
+// All returns all the annotations in the index in the order they were added.
+func (s *Annotations) All() []*Annotation {
+	return s.annotations
+}
+
+// Genes returns the identifiers of all the annotated gene products in the
+// index, sorted lexically.
+func (s *Annotations) Genes() []string {
+	genes := make([]string, 0, len(s.byGene))
+	for g := range s.byGene {
+		genes = append(genes, g)
+	}
+	sort.Strings(genes)
+	return genes
+}
+
+// AnnotationsFor returns the annotations of the gene product with the given
+// identifier in the order they were added.
+func (s *Annotations) AnnotationsFor(gene string) []*Annotation {
+	return s.byGene[gene]
+}
+
+// AnnotationsTo returns the annotations directly to the term t in the order
+// they were added.
+func (s *Annotations) AnnotationsTo(t rdf.Term) []*Annotation {
+	return s.byTerm[t.UID]
+}
+
+// TermsFor returns the terms directly and positively annotated to the gene
+// product with the given identifier, sorted by value.
+func (s *Annotations) TermsFor(gene string) []rdf.Term {
+	pos, _ := s.direct(gene)
+	return pos
+}
+
+// GenesFor returns the identifiers of the gene products directly and
+// positively annotated to the term t, sorted lexically.
+func (s *Annotations) GenesFor(t rdf.Term) []string {
+	seen := make(map[string]bool)
+	var genes []string
+	for _, a := range s.byTerm[t.UID] {
+		if a.Negated || seen[a.GeneProduct.ID] {
+			continue
+		}
+		seen[a.GeneProduct.ID] = true
+		genes = append(genes, a.GeneProduct.ID)
+	}
+	sort.Strings(genes)
+	return genes
+}
+
+// PropagatedTermsFor returns the terms annotated to the gene product with
+// the given identifier after propagation by the true path rule, sorted by
+// value. Positive annotations propagate to the ancestors of the annotated
+// term, and NOT annotations remove the annotated term and its descendants
+// from the result.
+func (s *Annotations) PropagatedTermsFor(gene string) []rdf.Term {
+	pos, neg := s.direct(gene)
+
+	negated := make(map[int64]bool)
+	for _, t := range neg {
+		negated[t.UID] = true
+		for _, d := range s.ancestry.DescendantsOf(t) {
+			negated[d.Term.UID] = true
+		}
+	}
+
+	seen := make(map[int64]bool)
+	var terms []rdf.Term
+	add := func(t rdf.Term) {
+		if seen[t.UID] || negated[t.UID] {
+			return
+		}
+		seen[t.UID] = true
+		terms = append(terms, t)
+	}
+	for _, t := range pos {
+		add(t)
+		for _, a := range s.ancestry.AncestorsOf(t) {
+			add(a.Term)
+		}
+	}
+	sortTerms(terms)
+	return terms
+}
+
+// PropagatedGenesFor returns the identifiers of the gene products annotated
+// to the term t after propagation by the true path rule, sorted lexically.
+// Gene products positively annotated to t or any of its descendants are
+// included unless they have a NOT annotation to t or any of its ancestors.
+func (s *Annotations) PropagatedGenesFor(t rdf.Term) []string {
+	negated := make(map[string]bool)
+	for _, a := range s.byTerm[t.UID] {
+		if a.Negated {
+			negated[a.GeneProduct.ID] = true
+		}
+	}
+	for _, anc := range s.ancestry.AncestorsOf(t) {
+		for _, a := range s.byTerm[anc.Term.UID] {
+			if a.Negated {
+				negated[a.GeneProduct.ID] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var genes []string
+	add := func(annots []*Annotation) {
+		for _, a := range annots {
+			id := a.GeneProduct.ID
+			if a.Negated || seen[id] || negated[id] {
+				continue
+			}
+			seen[id] = true
+			genes = append(genes, id)
+		}
+	}
+	add(s.byTerm[t.UID])
+	for _, d := range s.ancestry.DescendantsOf(t) {
+		add(s.byTerm[d.Term.UID])
+	}
+	sort.Strings(genes)
+	return genes
+}
+
+// direct returns the terms directly annotated to the gene product with the
+// given identifier, split into positive and negated annotations and sorted
+// by value.
+func (s *Annotations) direct(gene string) (pos, neg []rdf.Term) {
+	seenPos := make(map[int64]bool)
+	seenNeg := make(map[int64]bool)
+	for _, a := range s.byGene[gene] {
+		if a.Negated {
+			if !seenNeg[a.Term.UID] {
+				seenNeg[a.Term.UID] = true
+				neg = append(neg, a.Term)
+			}
+			continue
+		}
+		if !seenPos[a.Term.UID] {
+			seenPos[a.Term.UID] = true
+			pos = append(pos, a.Term)
+		}
+	}
+	sortTerms(pos)
+	sortTerms(neg)
+	return pos, neg
+}
@@ -0,0 +1,82 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/kortschak/gogo"
+)
+
+func ExampleAnnotations() {
+	// Build an annotation index from a GAF file and list the
+	// terms annotated to a gene product, including the terms
+	// inferred by the true path rule.
+
+	g := gogo.NewGraph()
+	_, err := g.LoadOBO(strings.NewReader(`format-version: 1.4
+ontology: go
+
+[Term]
+id: GO:0008150
+name: biological_process
+
+[Term]
+id: GO:0009987
+name: cellular process
+is_a: GO:0008150
+
+[Term]
+id: GO:0007154
+name: cell communication
+is_a: GO:0009987
+
+[Term]
+id: GO:0007165
+name: signal transduction
+is_a: GO:0009987
+relationship: part_of GO:0007154
+`), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gaf := `!gaf-version: 2.2
+UniProtKB	P12345	ABC1	involved_in	GO:0007165	PMID:1	IDA		P	ABC protein		protein	taxon:9606	20200102	UniProt		
+`
+	r, err := gogo.NewGAFReader(strings.NewReader(gaf), g)
+	if err != nil {
+		log.Fatal(err)
+	}
+	annotations := gogo.NewAnnotations(gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf})
+	for {
+		a, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = annotations.Add(a)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, t := range annotations.PropagatedTermsFor("UniProtKB:P12345") {
+		label, _ := g.Label(t)
+		fmt.Printf("%s %s\n", t.Value, label)
+	}
+
+	// Output:
+	//
+	// <http://purl.obolibrary.org/obo/GO_0007154> cell communication
+	// <http://purl.obolibrary.org/obo/GO_0007165> signal transduction
+	// <http://purl.obolibrary.org/obo/GO_0008150> biological_process
+	// <http://purl.obolibrary.org/obo/GO_0009987> cellular process
+}
@@ -0,0 +1,125 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"testing"
+
+	"github.com/kortschak/gogo"
+)
+
+var storeAnnotations = []struct {
+	gene    string
+	term    string
+	negated bool
+}{
+	{gene: "g1", term: "GO:0007165"},
+	{gene: "g2", term: "GO:0007165"},
+	{gene: "g2", term: "GO:0007154", negated: true},
+	{gene: "g3", term: "GO:0010646"},
+	{gene: "g3", term: "GO:0010646"},
+	{gene: "g3", term: "GO:0009987", negated: true},
+}
+
+func annotationStore(t *testing.T, a gogo.Ancestry) *gogo.Annotations {
+	t.Helper()
+	s := gogo.NewAnnotations(a)
+	for _, a := range storeAnnotations {
+		err := s.Add(&gogo.Annotation{
+			GeneProduct: gogo.GeneProduct{ID: a.gene},
+			TermID:      a.term,
+			Negated:     a.negated,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return s
+}
+
+func TestAnnotations(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, ancestryOBO, local)
+		s := annotationStore(t, gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf})
+
+		err := s.Add(&gogo.Annotation{GeneProduct: gogo.GeneProduct{ID: "g4"}, TermID: "GO:9999999"})
+		if err == nil {
+			t.Error("expected error for unknown term")
+		}
+		if s.Len() != len(storeAnnotations) {
+			t.Errorf("unexpected number of annotations: got:%d want:%d", s.Len(), len(storeAnnotations))
+		}
+		if got, want := s.Genes(), []string{"g1", "g2", "g3"}; !equalStrings(got, want) {
+			t.Errorf("unexpected genes: got:%v want:%v", got, want)
+		}
+
+		terms := func(ids ...string) []string {
+			var v []string
+			for _, id := range ids {
+				v = append(v, goTerm(id, local))
+			}
+			return v
+		}
+		for _, test := range []struct {
+			gene       string
+			direct     []string
+			propagated []string
+		}{
+			{
+				gene:       "g1",
+				direct:     terms("0007165"),
+				propagated: terms("0007154", "0007165", "0008150", "0009987"),
+			},
+			{
+				gene:       "g2",
+				direct:     terms("0007165"),
+				propagated: terms("0008150", "0009987"),
+			},
+			{
+				gene:       "g3",
+				direct:     terms("0010646"),
+				propagated: terms("0008150", "0010646", "0050789", "0065007"),
+			},
+			{
+				gene: "g4",
+			},
+		} {
+			got := termValues(s.TermsFor(test.gene))
+			if !equalStrings(got, test.direct) {
+				t.Errorf("unexpected direct terms for %s local=%t:\ngot: %v\nwant:%v", test.gene, local, got, test.direct)
+			}
+			got = termValues(s.PropagatedTermsFor(test.gene))
+			if !equalStrings(got, test.propagated) {
+				t.Errorf("unexpected propagated terms for %s local=%t:\ngot: %v\nwant:%v", test.gene, local, got, test.propagated)
+			}
+		}
+
+		for _, test := range []struct {
+			term       string
+			direct     []string
+			propagated []string
+		}{
+			{term: "0007165", direct: []string{"g1", "g2"}, propagated: []string{"g1"}},
+			{term: "0007154", propagated: []string{"g1"}},
+			{term: "0009987", propagated: []string{"g1", "g2"}},
+			{term: "0008150", propagated: []string{"g1", "g2", "g3"}},
+			{term: "0010646", direct: []string{"g3"}, propagated: []string{"g3"}},
+			{term: "0010647"},
+		} {
+			term, ok := g.TermFor(goTerm(test.term, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.term)
+			}
+			got := s.GenesFor(term)
+			if !equalStrings(got, test.direct) {
+				t.Errorf("unexpected direct genes for %s local=%t:\ngot: %v\nwant:%v", test.term, local, got, test.direct)
+			}
+			got = s.PropagatedGenesFor(term)
+			if !equalStrings(got, test.propagated) {
+				t.Errorf("unexpected propagated genes for %s local=%t:\ngot: %v\nwant:%v", test.term, local, got, test.propagated)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// TermInfo holds the descriptive metadata of an ontology term.
+type TermInfo struct {
+	// Term is the described term.
+	Term rdf.Term
+
+	// ID is the OBO identifier of the term,
+	// for example "GO:0008150".
+	ID string
+
+	// Label is the rdfs:label of the term.
+	Label string
+
+	// Definition is the obo:IAO_0000115
+	// definition of the term.
+	Definition string
+
+	// Namespace is the OBO namespace of the
+	// term, for example "biological_process".
+	Namespace string
+
+	// Synonyms holds the synonyms of the term
+	// sorted by scope and then text.
+	Synonyms []Synonym
+
+	// Xrefs holds the sorted database cross
+	// references of the term.
+	Xrefs []string
+
+	// Comments holds the sorted rdfs:comment
+	// texts of the term.
+	Comments []string
+}
+
+// Synonym is an ontology term synonym.
+type Synonym struct {
+	// Text is the text of the synonym.
+	Text string
+
+	// Scope is the OBO scope of the synonym,
+	// one of "EXACT", "RELATED", "NARROW" or
+	// "BROAD".
+	Scope string
+}
+
+// scopeOrder is the ordered set of OBO synonym scopes.
+var scopeOrder = []string{"EXACT", "RELATED", "NARROW", "BROAD"}
+
+// TermInfo returns the descriptive metadata held in g for the term t. If t
+// is not a node in g, ok is false. Where a term has more than one value for
+// a single valued field, the lexically first value is used.
+func (g *Graph) TermInfo(t rdf.Term) (info TermInfo, ok bool) {
+	if g.Node(t.UID) == nil {
+		return info, false
+	}
+	info = TermInfo{
+		Term:       t,
+		ID:         first(g.literals(t, "oboInOwl:id")),
+		Label:      first(g.literals(t, "rdfs:label")),
+		Definition: first(g.literals(t, "obo:IAO_0000115")),
+		Namespace:  first(g.literals(t, "oboInOwl:hasOBONamespace")),
+		Synonyms:   g.Synonyms(t),
+		Xrefs:      sorted(g.literals(t, "oboInOwl:hasDbXref")),
+		Comments:   sorted(g.literals(t, "rdfs:comment")),
+	}
+	return info, true
+}
+
+// Label returns the rdfs:label of the term t, and whether it has a label.
+func (g *Graph) Label(t rdf.Term) (label string, ok bool) {
+	labels := g.literals(t, "rdfs:label")
+	if len(labels) == 0 {
+		return "", false
+	}
+	return first(labels), true
+}
+
+// Synonyms returns the synonyms of the term t sorted by scope and then text.
+func (g *Graph) Synonyms(t rdf.Term) []Synonym {
+	var syns []Synonym
+	for _, scope := range scopeOrder {
+		for _, text := range sorted(g.literals(t, synonymPredicates[scope])) {
+			syns = append(syns, Synonym{Text: text, Scope: scope})
+		}
+	}
+	return syns
+}
+
+// first returns the lexically first element of s, or the empty string if
+// s is empty.
+func first(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return sorted(s)[0]
+}
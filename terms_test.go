@@ -0,0 +1,73 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+
+	"github.com/kortschak/gogo"
+)
+
+var termInfoTests = []struct {
+	id   string
+	want gogo.TermInfo
+}{
+	{
+		id: "0008150",
+		want: gogo.TermInfo{
+			ID:         "GO:0008150",
+			Label:      "biological_process",
+			Definition: "A biological process is the execution of a genetically-encoded biological module or program.",
+			Namespace:  "biological_process",
+			Synonyms: []gogo.Synonym{
+				{Text: "biological process", Scope: "EXACT"},
+				{Text: "physiological process", Scope: "RELATED"},
+			},
+			Comments: []string{"Note that, in addition to forming the root of the biological process ontology..."},
+		},
+	},
+	{
+		id: "0007165",
+		want: gogo.TermInfo{
+			ID:        "GO:0007165",
+			Label:     "signal transduction",
+			Namespace: "biological_process",
+			Xrefs:     []string{"Wikipedia:Signal_transduction"},
+		},
+	},
+}
+
+func TestTermInfo(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		g := oboGraph(t, testOBO, local)
+		for _, test := range termInfoTests {
+			term, ok := g.TermFor(goTerm(test.id, local))
+			if !ok {
+				t.Fatalf("missing term for %q", test.id)
+			}
+			got, ok := g.TermInfo(term)
+			if !ok {
+				t.Errorf("no term info for %q local=%t", test.id, local)
+				continue
+			}
+			test.want.Term = term
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("unexpected term info for %q local=%t:\ngot: %#v\nwant:%#v", test.id, local, got, test.want)
+			}
+			label, ok := g.Label(term)
+			if !ok || label != test.want.Label {
+				t.Errorf("unexpected label for %q local=%t: got:%q want:%q", test.id, local, label, test.want.Label)
+			}
+		}
+	}
+
+	g := gogo.NewGraph()
+	if _, ok := g.TermInfo(rdf.Term{Value: "<ex:missing>", UID: 1}); ok {
+		t.Error("unexpected term info for missing term")
+	}
+}
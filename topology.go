@@ -0,0 +1,390 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// EnrichmentMethod specifies an enrichment analysis algorithm.
+type EnrichmentMethod int
+
+const (
+	// Classic tests each term independently
+	// against the whole population.
+	Classic EnrichmentMethod = iota
+
+	// ParentChildUnion is the parent-child
+	// method of Grossmann et al. (2007). Each
+	// term is tested against the gene products
+	// annotated to any of its parents rather
+	// than the whole population.
+	ParentChildUnion
+
+	// ParentChildIntersection is the
+	// parent-child method tested against the
+	// gene products annotated to all of the
+	// term's parents.
+	ParentChildIntersection
+
+	// Elim is the elim method of Alexa et al.
+	// (2006). Terms are tested from the leaves
+	// of the ontology upwards and the gene
+	// products annotated to a term that is
+	// significant at the options' Cutoff are
+	// removed from the annotations of all its
+	// ancestors. The study and population
+	// counts of Elim results are those that
+	// remain after removal, and may be zero.
+	Elim
+
+	// Weight is the weight method of Alexa et
+	// al. (2006). Terms are tested from the
+	// leaves of the ontology upwards and gene
+	// products are down-weighted in the less
+	// significant of each parent and child
+	// pair by the ratio of their p-values.
+	// The study and population counts of
+	// Weight results are the rounded sums of
+	// gene product weights and their Genes
+	// are all the study gene products
+	// annotated to the term.
+	Weight
+)
+
+// String returns the name of the method.
+func (m EnrichmentMethod) String() string {
+	switch m {
+	case Classic:
+		return "classic"
+	case ParentChildUnion:
+		return "parent-child-union"
+	case ParentChildIntersection:
+		return "parent-child-intersection"
+	case Elim:
+		return "elim"
+	case Weight:
+		return "weight"
+	default:
+		return "unknown"
+	}
+}
+
+// enrichmentDAG is the subgraph of the ontology induced by the terms
+// annotated to a population of gene products.
+type enrichmentDAG struct {
+	// terms holds the annotated terms.
+	terms map[int64]rdf.Term
+	// genes holds the sorted population gene
+	// products annotated to each term, and
+	// members holds them as a set.
+	genes   map[int64][]string
+	members map[int64]map[string]bool
+
+	// parents and children hold the annotated
+	// terms directly related to each term.
+	parents, children map[int64][]int64
+	// ancestors caches the transitive closure
+	// of parents.
+	ancestors map[int64][]int64
+
+	// order holds the annotated terms ordered
+	// so that each term follows all of its
+	// descendants.
+	order []int64
+}
+
+// topology returns the annotated term graph of the population, constructing
+// it if necessary. Terms are related by the relations followed by the
+// population's ancestry.
+func (p *enrichmentPopulation) topology() *enrichmentDAG {
+	if p.dag != nil {
+		return p.dag
+	}
+	d := &enrichmentDAG{
+		terms:     make(map[int64]rdf.Term),
+		genes:     make(map[int64][]string),
+		members:   make(map[int64]map[string]bool),
+		parents:   make(map[int64][]int64),
+		children:  make(map[int64][]int64),
+		ancestors: make(map[int64][]int64),
+	}
+	for _, g := range p.genes {
+		for _, t := range p.terms[g] {
+			d.terms[t.UID] = t
+			d.genes[t.UID] = append(d.genes[t.UID], g)
+			m, ok := d.members[t.UID]
+			if !ok {
+				m = make(map[string]bool)
+				d.members[t.UID] = m
+			}
+			m[g] = true
+		}
+	}
+
+	terms := make([]rdf.Term, 0, len(d.terms))
+	for _, t := range d.terms {
+		terms = append(terms, t)
+	}
+	sortTerms(terms)
+	for _, t := range terms {
+		seen := make(map[int64]bool)
+		for _, l := range p.ancestry.parents(t) {
+			uid := l.term.UID
+			if _, ok := d.terms[uid]; !ok || uid == t.UID || seen[uid] {
+				continue
+			}
+			seen[uid] = true
+			d.parents[t.UID] = append(d.parents[t.UID], uid)
+			d.children[uid] = append(d.children[uid], t.UID)
+		}
+	}
+
+	// Order terms from the leaves upwards
+	// using Kahn's algorithm.
+	pending := make(map[int64]int)
+	var ready []int64
+	for _, t := range terms {
+		pending[t.UID] = len(d.children[t.UID])
+		if pending[t.UID] == 0 {
+			ready = append(ready, t.UID)
+		}
+	}
+	for len(ready) != 0 {
+		uid := ready[0]
+		ready = ready[1:]
+		d.order = append(d.order, uid)
+		for _, par := range d.parents[uid] {
+			pending[par]--
+			if pending[par] == 0 {
+				ready = append(ready, par)
+			}
+		}
+	}
+
+	p.dag = d
+	return d
+}
+
+// ancestorsOf returns the annotated ancestors of the term with the given
+// UID, excluding the term itself.
+func (d *enrichmentDAG) ancestorsOf(uid int64) []int64 {
+	if anc, ok := d.ancestors[uid]; ok {
+		return anc
+	}
+	seen := map[int64]bool{uid: true}
+	var anc []int64
+	queue := []int64{uid}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, par := range d.parents[u] {
+			if seen[par] {
+				continue
+			}
+			seen[par] = true
+			anc = append(anc, par)
+			queue = append(queue, par)
+		}
+	}
+	d.ancestors[uid] = anc
+	return anc
+}
+
+// parentGenes returns the population gene products annotated to any of
+// the parents of the term with the given UID, or to all of them if
+// intersection is true. If the term has no parents, parentGenes returns
+// nil and false.
+func (d *enrichmentDAG) parentGenes(uid int64, intersection bool) ([]string, bool) {
+	parents := d.parents[uid]
+	if len(parents) == 0 {
+		return nil, false
+	}
+	var genes []string
+	if intersection {
+	outer:
+		for _, g := range d.genes[parents[0]] {
+			for _, par := range parents[1:] {
+				if !d.members[par][g] {
+					continue outer
+				}
+			}
+			genes = append(genes, g)
+		}
+		return genes, true
+	}
+	seen := make(map[string]bool)
+	for _, par := range parents {
+		for _, g := range d.genes[par] {
+			if !seen[g] {
+				seen[g] = true
+				genes = append(genes, g)
+			}
+		}
+	}
+	return genes, true
+}
+
+// parentChild returns the parent-child over-representation results for
+// the terms annotated to at least one study gene product.
+func (p *enrichmentPopulation) parentChild(inStudy map[string]bool, intersection bool) []Enrichment {
+	d := p.topology()
+	var results []Enrichment
+	for _, uid := range d.order {
+		genes := studyGenes(d.genes[uid], inStudy)
+		if len(genes) == 0 {
+			continue
+		}
+		N := len(p.genes)
+		n := len(inStudy)
+		parents, ok := d.parentGenes(uid, intersection)
+		pN, pn := N, n
+		if ok {
+			pN = len(parents)
+			pn = len(studyGenes(parents, inStudy))
+		}
+		k := len(genes)
+		K := len(d.genes[uid])
+		e := newEnrichment(d.terms[uid], genes, k, pn, K, pN, hypergeometricUpper(k, pn, K, pN))
+		e.StudySize, e.PopulationSize = n, N
+		if ok {
+			e.ParentStudySize, e.ParentPopulationSize = pn, pN
+		}
+		results = append(results, e)
+	}
+	return results
+}
+
+// elim returns the elim over-representation results for the terms
+// annotated to at least one study gene product before removal.
+func (p *enrichmentPopulation) elim(inStudy map[string]bool, cutoff float64) []Enrichment {
+	d := p.topology()
+	N := len(p.genes)
+	n := len(inStudy)
+	removed := make(map[int64]map[string]bool)
+	var results []Enrichment
+	for _, uid := range d.order {
+		var remaining []string
+		for _, g := range d.genes[uid] {
+			if !removed[uid][g] {
+				remaining = append(remaining, g)
+			}
+		}
+		genes := studyGenes(remaining, inStudy)
+		k := len(genes)
+		K := len(remaining)
+		pv := hypergeometricUpper(k, n, K, N)
+		if pv < cutoff {
+			for _, anc := range d.ancestorsOf(uid) {
+				r, ok := removed[anc]
+				if !ok {
+					r = make(map[string]bool)
+					removed[anc] = r
+				}
+				for _, g := range remaining {
+					r[g] = true
+				}
+			}
+		}
+		if len(studyGenes(d.genes[uid], inStudy)) != 0 {
+			results = append(results, newEnrichment(d.terms[uid], genes, k, n, K, N, pv))
+		}
+	}
+	return results
+}
+
+// weight returns the weight over-representation results for the terms
+// annotated to at least one study gene product.
+func (p *enrichmentPopulation) weight(inStudy map[string]bool) []Enrichment {
+	d := p.topology()
+	N := len(p.genes)
+	n := len(inStudy)
+
+	// weights holds the gene product weights
+	// for each term. Absent weights are one.
+	weights := make(map[int64]map[string]float64)
+	weight := func(uid int64, g string) float64 {
+		w, ok := weights[uid][g]
+		if !ok {
+			return 1
+		}
+		return w
+	}
+	scale := func(uid int64, genes []string, f float64) {
+		w, ok := weights[uid]
+		if !ok {
+			w = make(map[string]float64)
+			weights[uid] = w
+		}
+		for _, g := range genes {
+			w[g] = weight(uid, g) * f
+		}
+	}
+	score := func(uid int64) (k, K int, pv float64) {
+		var sk, sK float64
+		for _, g := range d.genes[uid] {
+			w := weight(uid, g)
+			sK += w
+			if inStudy[g] {
+				sk += w
+			}
+		}
+		k = int(math.Round(sk))
+		K = int(math.Round(sK))
+		return k, K, hypergeometricUpper(k, n, K, N)
+	}
+
+	pv := make(map[int64]float64)
+	for _, uid := range d.order {
+		_, _, pv[uid] = score(uid)
+		for _, c := range d.children[uid] {
+			r := pv[uid] / pv[c]
+			switch {
+			case math.IsNaN(r):
+				// Both p-values are zero.
+				continue
+			case r <= 1:
+				// The parent is at least as significant
+				// as the child, so down-weight the child.
+				scale(c, d.genes[c], r)
+				_, _, pv[c] = score(c)
+			default:
+				// The child is more significant, so
+				// down-weight its gene products in the
+				// parent and the parent's ancestors.
+				scale(uid, d.genes[c], 1/r)
+				for _, anc := range d.ancestorsOf(uid) {
+					scale(anc, d.genes[c], 1/r)
+				}
+			}
+		}
+		_, _, pv[uid] = score(uid)
+	}
+
+	var results []Enrichment
+	for _, uid := range d.order {
+		genes := studyGenes(d.genes[uid], inStudy)
+		if len(genes) == 0 {
+			continue
+		}
+		k, K, _ := score(uid)
+		results = append(results, newEnrichment(d.terms[uid], genes, k, n, K, N, pv[uid]))
+	}
+	return results
+}
+
+// studyGenes returns the gene products in genes that are in the study,
+// retaining their order.
+func studyGenes(genes []string, inStudy map[string]bool) []string {
+	var study []string
+	for _, g := range genes {
+		if inStudy[g] {
+			study = append(study, g)
+		}
+	}
+	return study
+}
@@ -0,0 +1,133 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kortschak/gogo"
+)
+
+// topologyStore returns an annotation store for a population of 45 gene
+// products and a study of eight gene products all annotated to signal
+// transduction.
+func topologyStore(t *testing.T, local bool) (s *gogo.Annotations, study, population []string) {
+	t.Helper()
+	g := oboGraph(t, ancestryOBO, local)
+	s = gogo.NewAnnotations(gogo.Ancestry{Graph: g, Relations: gogo.IsAPartOf})
+	for i := 0; i < 45; i++ {
+		gene := fmt.Sprintf("p%d", i)
+		var term string
+		switch {
+		case i < 10:
+			term = "GO:0007165"
+		case i < 20:
+			term = "GO:0007154"
+		case i < 30:
+			term = "GO:0010646"
+		case i < 40:
+			term = "GO:0008150"
+		default:
+			term = "GO:0009987"
+		}
+		err := s.Add(&gogo.Annotation{GeneProduct: gogo.GeneProduct{ID: gene}, TermID: term})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		population = append(population, gene)
+		if i < 8 {
+			study = append(study, gene)
+		}
+	}
+	return s, study, population
+}
+
+var topologyTests = []struct {
+	method gogo.EnrichmentMethod
+	want   []string
+}{
+	{
+		method: gogo.Classic,
+		want: []string{
+			"0007165 8/8 10/45 0/0 2.088e-07",
+			"0007154 8/8 20/45 0/0 0.0005844",
+			"0009987 8/8 25/45 0/0 0.005018",
+			"0008150 8/8 45/45 0/0 1",
+		},
+	},
+	{
+		method: gogo.ParentChildUnion,
+		want: []string{
+			"0007165 8/8 10/45 8/25 4.161e-05",
+			"0009987 8/8 25/45 8/45 0.005018",
+			"0007154 8/8 20/45 8/25 0.1165",
+			"0008150 8/8 45/45 0/0 1",
+		},
+	},
+	{
+		method: gogo.ParentChildIntersection,
+		want: []string{
+			"0007165 8/8 10/45 8/20 0.0003572",
+			"0009987 8/8 25/45 8/45 0.005018",
+			"0007154 8/8 20/45 8/25 0.1165",
+			"0008150 8/8 45/45 0/0 1",
+		},
+	},
+	{
+		method: gogo.Elim,
+		want: []string{
+			"0007165 8/8 10/45 0/0 2.088e-07",
+			"0007154 0/8 10/45 0/0 1",
+			"0008150 0/8 35/45 0/0 1",
+			"0009987 0/8 15/45 0/0 1",
+		},
+	},
+	{
+		method: gogo.Weight,
+		want: []string{
+			"0007165 8/8 10/45 0/0 2.088e-07",
+			"0007154 0/8 10/45 0/0 1",
+			"0008150 0/8 35/45 0/0 1",
+			"0009987 0/8 15/45 0/0 1",
+		},
+	},
+}
+
+func TestTopologyEnrichment(t *testing.T) {
+	for _, local := range []bool{false, true} {
+		s, study, population := topologyStore(t, local)
+		for _, test := range topologyTests {
+			results, err := s.Enrichment(study, population, &gogo.EnrichmentOptions{Method: test.method})
+			if err != nil {
+				t.Fatalf("unexpected error for %v: %v", test.method, err)
+			}
+			var got []string
+			for _, e := range results {
+				id := e.Term.Value[strings.Index(e.Term.Value, "GO_")+3 : len(e.Term.Value)-1]
+				got = append(got, fmt.Sprintf("%s %d/%d %d/%d %d/%d %.4g",
+					id, e.StudyCount, e.StudySize, e.PopulationCount, e.PopulationSize,
+					e.ParentStudySize, e.ParentPopulationSize, e.P))
+			}
+			if !equalStrings(got, test.want) {
+				t.Errorf("unexpected %v enrichment local=%t:\ngot: %q\nwant:%q", test.method, local, got, test.want)
+			}
+		}
+	}
+}
+
+func TestEnrichmentInvalidMethod(t *testing.T) {
+	s, study, population := topologyStore(t, false)
+	opts := &gogo.EnrichmentOptions{Method: gogo.Weight + 1}
+	_, err := s.Enrichment(study, population, opts)
+	if err == nil {
+		t.Error("expected error for invalid method")
+	}
+	err = s.PermutationFDR([]gogo.Enrichment{{}}, study, population, opts, 1, nil)
+	if err == nil {
+		t.Error("expected error for invalid method from PermutationFDR")
+	}
+}
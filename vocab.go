@@ -0,0 +1,105 @@
+// Copyright ©2020 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogo
+
+import (
+	"strings"
+
+	"gonum.org/v1/gonum/graph/formats/rdf"
+)
+
+// prefixes is the mapping from qualified name prefixes to
+// their global IRI namespaces.
+var prefixes = map[string]string{
+	"obo":      "http://purl.obolibrary.org/obo/",
+	"oboInOwl": "http://www.geneontology.org/formats/oboInOwl#",
+	"owl":      "http://www.w3.org/2002/07/owl#",
+	"rdf":      "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":     "http://www.w3.org/2000/01/rdf-schema#",
+	"xsd":      "http://www.w3.org/2001/XMLSchema#",
+}
+
+// expand returns the IRI for the qualified name qname in the given
+// namespace form without enclosing angle brackets. If the prefix of
+// qname is not known, qname is returned unaltered.
+func expand(namespace int, qname string) string {
+	if namespace == local {
+		return qname
+	}
+	i := strings.Index(qname, ":")
+	if i < 0 {
+		return qname
+	}
+	base, ok := prefixes[qname[:i]]
+	if !ok {
+		return qname
+	}
+	return base + qname[i+1:]
+}
+
+// iriTerm returns the RDF term value for the qualified name qname in the
+// given namespace form.
+func iriTerm(namespace int, qname string) string {
+	return "<" + expand(namespace, qname) + ">"
+}
+
+// compact returns the qualified name for the global IRI iri if its
+// namespace is known, and iri otherwise.
+func compact(iri string) string {
+	for prefix, base := range prefixes {
+		if strings.HasPrefix(iri, base) {
+			return prefix + ":" + iri[len(base):]
+		}
+	}
+	return iri
+}
+
+// oboQName returns the qualified name for the OBO identifier id. Prefixed
+// identifiers such as "GO:0008150" are returned in the obo namespace as
+// "obo:GO_0008150". Identifiers that are already IRIs are returned as
+// qualified names if their namespace is known and unaltered otherwise,
+// and unprefixed identifiers are placed in the ontology's namespace.
+func oboQName(id, ontology string) string {
+	if strings.Contains(id, "://") {
+		return compact(id)
+	}
+	i := strings.Index(id, ":")
+	if i < 0 {
+		return "obo:" + ontology + "#" + id
+	}
+	return "obo:" + id[:i] + "_" + id[i+1:]
+}
+
+// objects returns the objects of statements in g with the subject t and
+// the predicate with the qualified name pred.
+func (g *Graph) objects(t rdf.Term, pred string) []rdf.Term {
+	p := iriTerm(g.namespace, pred)
+	return g.Query(t).Out(func(s *rdf.Statement) bool {
+		return s.Predicate.Value == p
+	}).Result()
+}
+
+// literals returns the text of the literal objects of statements in g with
+// the subject t and the predicate with the qualified name pred.
+func (g *Graph) literals(t rdf.Term, pred string) []string {
+	var text []string
+	for _, o := range g.objects(t, pred) {
+		v, _, kind, err := o.Parts()
+		if err != nil || kind != rdf.Literal {
+			continue
+		}
+		text = append(text, v)
+	}
+	return text
+}
+
+// subjects returns the subjects of statements in g with the object t and
+// the predicate with the qualified name pred.
+func (g *Graph) subjects(t rdf.Term, pred string) []rdf.Term {
+	p := iriTerm(g.namespace, pred)
+	return g.Query(t).In(func(s *rdf.Statement) bool {
+		return s.Predicate.Value == p
+	}).Result()
+}